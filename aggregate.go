@@ -0,0 +1,217 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// aggregates accepted by the agg tag option
+var aggregates = map[string]bool{
+	"sum":   true,
+	"avg":   true,
+	"min":   true,
+	"max":   true,
+	"count": true,
+}
+
+// buildFooter returns the footer row with the aggregate of each column
+// with the agg tag option, such as `header:"Bytes,agg=sum"`.  Aggregates
+// are calculated from the field values, not the formatted strings.
+// Returns nil if no column has the agg tag option.
+func buildFooter(tables []TableStruct, rowType reflect.Type, fields []string, opts []tagOptions) ([]string, error) {
+	var footer []string
+	for i, field := range fields {
+		agg, ok := opts[i]["agg"]
+		if !ok {
+			continue
+		}
+		if footer == nil {
+			footer = make([]string, len(fields))
+		}
+		value, err := aggregate(agg, tables, field, opts[i])
+		if err != nil {
+			return nil, fmt.Errorf("Unable to aggregate field '%s' in %s: %s", field, rowType.Name(), err.Error())
+		}
+		footer[i] = value
+	}
+	return footer, nil
+}
+
+// aggregate calculates agg over the given field of every non-nil row.
+// Integers are summed as integers, durations as durations & floats are
+// rounded to the most decimals of any value so 0.1 + 0.2 is 0.3.
+func aggregate(agg string, tables []TableStruct, field string, opts tagOptions) (string, error) {
+	if !aggregates[agg] {
+		return "", fmt.Errorf("unknown agg '%s'", agg)
+	}
+
+	cnt := 0
+	values := []reflect.Value{}
+	for _, item := range tables {
+		if isNilRow(item) {
+			continue
+		}
+		fval := reflect.Indirect(reflect.ValueOf(item)).FieldByName(field)
+		if !fval.IsValid() {
+			continue
+		}
+		cnt++
+		if v := indirectValue(fval); v.IsValid() {
+			values = append(values, v)
+		}
+	}
+
+	if agg == "count" {
+		return strconv.Itoa(cnt), nil
+	}
+	if len(values) == 0 {
+		return "", nil
+	}
+	for _, v := range values[1:] {
+		if v.Type() != values[0].Type() {
+			return "", fmt.Errorf("mixed types %s and %s", values[0].Type(), v.Type())
+		}
+	}
+
+	switch values[0].Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if values[0].Type() == durationType {
+			return aggDurations(agg, values, opts)
+		}
+		return aggInts(agg, values), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return aggUints(agg, values), nil
+	case reflect.Float32, reflect.Float64:
+		return aggFloats(agg, values), nil
+	}
+	return "", fmt.Errorf("non-numeric kind %s", values[0].Kind())
+}
+
+// intStats returns the sum, min & max of signed integer values
+func intStats(values []reflect.Value) (sum, min, max int64) {
+	for i, v := range values {
+		n := v.Int()
+		if i == 0 || n < min {
+			min = n
+		}
+		if i == 0 || n > max {
+			max = n
+		}
+		sum += n
+	}
+	return sum, min, max
+}
+
+func aggInts(agg string, values []reflect.Value) string {
+	sum, min, max := intStats(values)
+	switch agg {
+	case "avg":
+		return formatFloat(float64(sum)/float64(len(values)), 64)
+	case "min":
+		return strconv.FormatInt(min, 10)
+	case "max":
+		return strconv.FormatInt(max, 10)
+	}
+	return strconv.FormatInt(sum, 10)
+}
+
+func aggUints(agg string, values []reflect.Value) string {
+	var sum, min, max uint64
+	for i, v := range values {
+		n := v.Uint()
+		if i == 0 || n < min {
+			min = n
+		}
+		if i == 0 || n > max {
+			max = n
+		}
+		sum += n
+	}
+	switch agg {
+	case "avg":
+		return formatFloat(float64(sum)/float64(len(values)), 64)
+	case "min":
+		return strconv.FormatUint(min, 10)
+	case "max":
+		return strconv.FormatUint(max, 10)
+	}
+	return strconv.FormatUint(sum, 10)
+}
+
+// aggDurations renders the aggregate of time.Duration values like the
+// values themselves, honoring the unit tag option
+func aggDurations(agg string, values []reflect.Value, opts tagOptions) (string, error) {
+	sum, min, max := intStats(values)
+	d := time.Duration(sum)
+	switch agg {
+	case "avg":
+		d = time.Duration(sum / int64(len(values)))
+	case "min":
+		d = time.Duration(min)
+	case "max":
+		d = time.Duration(max)
+	}
+	return formatDuration(reflect.ValueOf(d), &fieldInfo{opts: opts}), nil
+}
+
+func aggFloats(agg string, values []reflect.Value) string {
+	bitSize := values[0].Type().Bits()
+	var sum, min, max float64
+	decimals := 0
+	for i, v := range values {
+		f := v.Float()
+		if i == 0 || f < min {
+			min = f
+		}
+		if i == 0 || f > max {
+			max = f
+		}
+		sum += f
+		if d := floatDecimals(f, bitSize); d > decimals {
+			decimals = d
+		}
+	}
+	// drop the rounding error of adding binary fractions
+	sum, _ = strconv.ParseFloat(strconv.FormatFloat(sum, 'f', decimals, 64), 64)
+	switch agg {
+	case "avg":
+		// 15 significant digits are exact for any float64
+		avg, _ := strconv.ParseFloat(strconv.FormatFloat(sum/float64(len(values)), 'g', 15, 64), 64)
+		return formatFloat(avg, 64)
+	case "min":
+		return formatFloat(min, bitSize)
+	case "max":
+		return formatFloat(max, bitSize)
+	}
+	return formatFloat(sum, 64)
+}
+
+// floatDecimals returns the number of decimals of the shortest
+// representation of f
+func floatDecimals(f float64, bitSize int) int {
+	s := strconv.FormatFloat(f, 'f', -1, bitSize)
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(s) - i - 1
+	}
+	return 0
+}
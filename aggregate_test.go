@@ -0,0 +1,120 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type statsRow struct {
+	Int      int64         `header:"Int,agg=sum"`
+	Uint     uint64        `header:"Uint,agg=max"`
+	Float    float64       `header:"Float,agg=sum"`
+	Float32  float32       `header:"Float32,agg=sum"`
+	Avg      float64       `header:"Avg,agg=avg"`
+	Took     time.Duration `header:"Took,agg=sum"`
+	TookMS   time.Duration `header:"TookMS,agg=avg,unit=ms"`
+	Count    *int          `header:"Count,agg=count"`
+	Min      *int          `header:"Min,agg=min"`
+	NoValues *int          `header:"NoValues,agg=sum"`
+}
+
+func (r statsRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+type badAggRow struct {
+	Bytes int `header:"Bytes,agg=total"`
+}
+
+func (r badAggRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+type stringAggRow struct {
+	Name string `header:"Name,agg=sum"`
+}
+
+func (r stringAggRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestAggregate(t *testing.T) {
+	five, two := 5, 2
+	rows := []TableStruct{
+		statsRow{
+			Int: math.MaxInt64 - 10, Uint: math.MaxUint64 - 1, Float: 0.1, Float32: 0.1, Avg: 0.1,
+			Took: 90 * time.Second, TookMS: time.Second, Count: &five, Min: &five,
+		},
+		statsRow{
+			Int: 9, Uint: 1, Float: 0.2, Float32: 0.2, Avg: 0.2,
+			Took: time.Minute, TookMS: 2 * time.Second, Min: &two,
+		},
+		nil,
+	}
+	o := newOptions(nil)
+	s, err := convertTables(rows, nil, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, err := s.table(nil, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"Int":      "9223372036854775806",
+		"Uint":     "18446744073709551614",
+		"Float":    "0.3",
+		"Float32":  "0.3",
+		"Avg":      "0.15",
+		"Took":     "2m30s",
+		"TookMS":   "1500ms",
+		"Count":    "2",
+		"Min":      "2",
+		"NoValues": "",
+	}
+	for i, field := range table.fields {
+		if table.footer[i] != want[field] {
+			t.Errorf("footer %s = %q, want %q", field, table.footer[i], want[field])
+		}
+	}
+}
+
+func TestAggregateErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		rows []TableStruct
+		err  string
+	}{
+		{"invalid agg", []TableStruct{badAggRow{Bytes: 1}}, "Invalid agg 'total' for field 'Bytes' in badAggRow"},
+		{"non-numeric", []TableStruct{stringAggRow{Name: "a"}}, "Unable to aggregate field 'Name' in stringAggRow: non-numeric kind string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GenerateTableString(tt.rows, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("GenerateTableString() error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// Align is the alignment of the values in a column
+type Align int
+
+const (
+	// AlignAuto right aligns numeric columns and left aligns the rest
+	AlignAuto Align = iota
+	// AlignLeft pads values on the right
+	AlignLeft
+	// AlignRight pads values on the left
+	AlignRight
+	// AlignCenter pads values on both sides.  Any odd padding goes on the
+	// right so every cell in the column leans the same way.
+	AlignCenter
+	// AlignDecimal right aligns values with their decimal points lined up
+	AlignDecimal
+)
+
+// aligns accepted by the align tag option
+var alignNames = map[string]Align{
+	"auto":    AlignAuto,
+	"left":    AlignLeft,
+	"right":   AlignRight,
+	"center":  AlignCenter,
+	"decimal": AlignDecimal,
+}
+
+// columnAlign returns the alignment of the field in the struct type t: the
+// WithAlignment or align tag option, if any, otherwise right for numeric
+// fields.  Types which implement fmt.Stringer are text, as are durations
+// without the unit tag option.
+func columnAlign(t reflect.Type, fieldName string, opts tagOptions, o *options) Align {
+	if align := o.aligns[fieldName]; align != AlignAuto {
+		return align
+	}
+	if align := alignNames[opts["align"]]; align != AlignAuto {
+		return align
+	}
+	if t == nil {
+		return AlignLeft
+	}
+	f, ok := t.FieldByName(fieldName)
+	if !ok {
+		return AlignLeft // derived column
+	}
+	if fi, err := newFieldInfo(t, f); err != nil || len(fi.enum) > 0 {
+		return AlignLeft // enums are rendered as labels
+	}
+	ft := f.Type
+	for {
+		if ft == durationType {
+			if opts.has("unit") {
+				return AlignRight // rendered as a number of units
+			}
+			return AlignLeft
+		}
+		if ft.Implements(stringerType) {
+			return AlignLeft // rendered as text by String()
+		}
+		if ft.Kind() != reflect.Ptr {
+			break
+		}
+		ft = ft.Elem()
+	}
+	switch ft.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return AlignRight
+	}
+	return AlignLeft
+}
+
+// alignAt returns the alignment of column i, which is left if unknown
+func alignAt(aligns []Align, i int) Align {
+	if i < len(aligns) {
+		return aligns[i]
+	}
+	return AlignLeft
+}
+
+// pad returns value padded with fill to width according to align
+func pad(value string, width int, align Align, fill string) string {
+	n := width - displayWidth(value)
+	if n <= 0 {
+		return value
+	}
+	switch align {
+	case AlignRight, AlignDecimal:
+		return strings.Repeat(fill, n) + value
+	case AlignCenter:
+		return strings.Repeat(fill, n/2) + value + strings.Repeat(fill, n-n/2)
+	}
+	return value + strings.Repeat(fill, n)
+}
+
+// alignDecimals returns t with the values of AlignDecimal columns padded so
+// their decimal points line up.  Values without a decimal point are aligned
+// as if it followed the last digit.
+func alignDecimals(t *tableData) *tableData {
+	intWidth, fracWidth := decimalWidths(t)
+	return padDecimals(t, intWidth, fracWidth)
+}
+
+// decimalWidths returns the width of the widest integer part & fraction of
+// each AlignDecimal column, including the footer
+func decimalWidths(t *tableData) ([]int, []int) {
+	intWidth := make([]int, len(t.aligns))
+	fracWidth := make([]int, len(t.aligns))
+	rows := t.rows
+	if t.footer != nil {
+		rows = append(rows[:len(rows):len(rows)], t.footer)
+	}
+	for col, align := range t.aligns {
+		if align != AlignDecimal {
+			continue
+		}
+		for _, row := range rows {
+			i, f := splitDecimal(row[col])
+			if w := utf8.RuneCountInString(i); w > intWidth[col] {
+				intWidth[col] = w
+			}
+			if w := utf8.RuneCountInString(f); w > fracWidth[col] {
+				fracWidth[col] = w
+			}
+		}
+	}
+	return intWidth, fracWidth
+}
+
+// padDecimals returns t with the values of AlignDecimal columns padded to
+// the given integer part & fraction widths.  Wider values are not padded.
+func padDecimals(t *tableData, intWidth, fracWidth []int) *tableData {
+	ret := *t
+	copied := false
+	for col, align := range t.aligns {
+		if align != AlignDecimal {
+			continue
+		}
+		if !copied {
+			ret.rows = make([][]string, len(t.rows))
+			for i, row := range t.rows {
+				ret.rows[i] = append([]string{}, row...)
+			}
+			if t.footer != nil {
+				ret.footer = append([]string{}, t.footer...)
+			}
+			copied = true
+		}
+
+		rows := ret.rows
+		if ret.footer != nil {
+			rows = append(rows[:len(rows):len(rows)], ret.footer)
+		}
+		for _, row := range rows {
+			if row[col] == "" {
+				continue
+			}
+			i, f := splitDecimal(row[col])
+			row[col] = padding(intWidth[col]-utf8.RuneCountInString(i)) + i +
+				f + padding(fracWidth[col]-utf8.RuneCountInString(f))
+		}
+	}
+	return &ret
+}
+
+// padding returns n spaces, none if n is negative
+func padding(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.Repeat(" ", n)
+}
+
+// splitDecimal splits value into the integer part & the decimal point with
+// the fraction
+func splitDecimal(value string) (string, string) {
+	if idx := strings.Index(value, "."); idx >= 0 {
+		return value[:idx], value[idx:]
+	}
+	return value, ""
+}
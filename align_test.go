@@ -0,0 +1,227 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type numericRow struct {
+	Name  string  `header:"Name"`
+	Count int     `header:"N"`
+	Ratio float64 `header:"R"`
+	Code  int     `header:"C,align=left"`
+}
+
+func (r numericRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestNumericAlignment(t *testing.T) {
+	rows := []TableStruct{
+		numericRow{Name: "a", Count: 7, Ratio: 1.5, Code: 7},
+		numericRow{Name: "bb", Count: 70000, Ratio: 10, Code: 70000},
+	}
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{
+			name: "auto",
+			want: "Name |     N |   R | C    \n==========================\na    |     7 | 1.5 | 7    \nbb   | 70000 |  10 | 70000\n",
+		},
+		{
+			name: "left aligned headers",
+			opts: []Option{WithLeftAlignedHeaders()},
+			want: "Name | N     | R   | C    \n==========================\na    |     7 | 1.5 | 7    \nbb   | 70000 |  10 | 70000\n",
+		},
+		{
+			name: "override",
+			opts: []Option{WithAlignment(map[string]Align{"Count": AlignLeft, "Name": AlignRight})},
+			want: "Name | N     |   R | C    \n==========================\n   a | 7     | 1.5 | 7    \n  bb | 70000 |  10 | 70000\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateTableString(rows, nil, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("GenerateTableString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := RenderMulti(rows, nil, []Output{{Format: FormatCSV, Writer: &buf}}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "a,7,1.5,7\nbb,70000,10,70000\n"; buf.String() != want {
+		t.Errorf("RenderMulti() = %q, want %q", buf.String(), want)
+	}
+}
+
+type centerRow struct {
+	Name string `header:"Name,align=center"`
+	Wide string `header:"Wide,align=center"`
+}
+
+func (r centerRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestCenterAlignment(t *testing.T) {
+	tests := []struct {
+		name string
+		rows []TableStruct
+		opts []Option
+		want string
+	}{
+		{
+			name: "odd & even padding",
+			rows: []TableStruct{
+				centerRow{Name: "a", Wide: "名"},
+				centerRow{Name: "abcd", Wide: "名前名前"},
+				centerRow{Name: "abc", Wide: "ab"},
+			},
+			want: "Name |   Wide  \n===============\n a   |    名   \nabcd | 名前名前\nabc  |    ab   \n",
+		},
+		{
+			name: "centered headers",
+			rows: []TableStruct{
+				numericRow{Name: "a", Count: 7, Ratio: 1.5, Code: 7},
+				numericRow{Name: "bb", Count: 70000, Ratio: 10, Code: 70000},
+			},
+			opts: []Option{WithHeaderAlignment(AlignCenter)},
+			want: "Name |   N   |  R  |   C  \n==========================\na    |     7 | 1.5 | 7    \nbb   | 70000 |  10 | 70000\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateTableString(tt.rows, nil, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("GenerateTableString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type stringerAlignRow struct {
+	Color color         `header:"Color"`
+	Point *point        `header:"Point"`
+	Count int           `header:"Count"`
+	Took  time.Duration `header:"Took"`
+	MS    time.Duration `header:"MS,unit=ms"`
+}
+
+func (r stringerAlignRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestStringerAlignment(t *testing.T) {
+	tests := []struct {
+		field string
+		want  Align
+	}{
+		{"Color", AlignLeft},
+		{"Point", AlignLeft},
+		{"Count", AlignRight},
+		{"Took", AlignLeft},
+		{"MS", AlignRight},
+	}
+	rowType := reflect.TypeOf(stringerAlignRow{})
+	for _, tt := range tests {
+		got := columnAlign(rowType, tt.field, fieldTagOptions(rowType, tt.field), newOptions(nil))
+		if got != tt.want {
+			t.Errorf("columnAlign(%s) = %d, want %d", tt.field, got, tt.want)
+		}
+	}
+
+	rows := []TableStruct{stringerAlignRow{Color: 2, Point: &point{1, 2}, Count: 1, Took: time.Second, MS: time.Second}}
+	got, err := GenerateTableString(rows, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Color | Point | Count | Took |     MS\n=====================================\nblue  | (1,2) |     1 | 1s   | 1000ms\n"
+	if got != want {
+		t.Errorf("GenerateTableString() = %q, want %q", got, want)
+	}
+}
+
+type receiptRow struct {
+	Item  string  `header:"Item,fill=."`
+	Price float64 `header:"Price,fill=·"`
+	Qty   int     `header:"Qty,fill=_,align=center"`
+}
+
+func (r receiptRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+type badFillRow struct {
+	Item string `header:"Item,fill=.."`
+}
+
+func (r badFillRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestFill(t *testing.T) {
+	rows := []TableStruct{receiptRow{"coffee", 3.5, 1}, receiptRow{"bagel with cheese", 12.25, 10}}
+	got, err := GenerateTableString(rows, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// headers are always padded with spaces
+	want := "Item              | Price | Qty\n===============================\n" +
+		"coffee........... | ··3.5 | _1_\nbagel with cheese | 12.25 | 10_\n"
+	if got != want {
+		t.Errorf("GenerateTableString() = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		value string
+		align Align
+		fill  string
+		want  string
+	}{
+		{"ab", AlignLeft, ".", "ab...."},
+		{"ab", AlignRight, "·", "····ab"},
+		{"ab", AlignCenter, "-", "--ab--"},
+		{"abc", AlignCenter, "-", "-abc--"},
+		{"abcdef", AlignLeft, ".", "abcdef"},
+	}
+	for _, tt := range tests {
+		if got := pad(tt.value, 6, tt.align, tt.fill); got != tt.want {
+			t.Errorf("pad(%q, %d, %q) = %q, want %q", tt.value, tt.align, tt.fill, got, tt.want)
+		}
+	}
+
+	_, err = GenerateTableString([]TableStruct{badFillRow{}}, nil)
+	if want := "Invalid fill '..' for field 'Item' in badFillRow"; err == nil || err.Error() != want {
+		t.Errorf("GenerateTableString() error = %v, want %q", err, want)
+	}
+}
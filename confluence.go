@@ -0,0 +1,72 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Generates Confluence/Jira wiki markup: ||header||header|| & |cell|cell|
+func GenerateConfluence(w io.Writer, tables []TableStruct, fields []string, opts ...Option) error {
+	t, err := buildTable(tables, fields, newOptions(opts))
+	if err != nil {
+		return err
+	}
+	return generateConfluence(w, t)
+}
+
+func generateConfluence(w io.Writer, t *tableData) error {
+	headers := make([]string, len(t.headers))
+	for i, header := range t.headers {
+		headers[i] = confluenceEscape(header)
+	}
+	if _, err := fmt.Fprintf(w, "||%s||\n", strings.Join(headers, "||")); err != nil {
+		return err
+	}
+
+	for _, row := range t.rows {
+		values := make([]string, len(row))
+		for i, value := range row {
+			values[i] = confluenceEscape(value)
+		}
+		if _, err := fmt.Fprintf(w, "|%s|\n", strings.Join(values, "|")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// confluenceEscaper escapes the cell separator & turns newlines, which
+// would end the row, into wiki line breaks
+var confluenceEscaper = strings.NewReplacer(
+	"|", "\\|",
+	"\r\n", "\\\\",
+	"\n", "\\\\",
+	"\r", "\\\\",
+)
+
+// confluenceEscape escapes value for a cell.  Empty cells are rendered as
+// a single space since the wiki markup collapses empty cells.
+func confluenceEscape(value string) string {
+	if value == "" {
+		return " "
+	}
+	return confluenceEscaper.Replace(value)
+}
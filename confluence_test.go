@@ -0,0 +1,49 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGenerateConfluence(t *testing.T) {
+	tests := []struct {
+		name string
+		row  colorRow
+		want string
+	}{
+		{"plain", colorRow{Name: "a", Value: "b"}, "|a|b|\n"},
+		{"empty", colorRow{Name: "", Value: "b"}, "| |b|\n"},
+		{"pipe", colorRow{Name: "a|b", Value: "c"}, "|a\\|b|c|\n"},
+		{"newline", colorRow{Name: "line1\nline2", Value: "c"}, "|line1\\\\line2|c|\n"},
+		{"crlf", colorRow{Name: "line1\r\nline2", Value: "c"}, "|line1\\\\line2|c|\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := GenerateConfluence(&buf, []TableStruct{tt.row}, nil); err != nil {
+				t.Fatal(err)
+			}
+			want := "||Name||Value||\n" + tt.want
+			if buf.String() != want {
+				t.Errorf("GenerateConfluence() = %q, want %q", buf.String(), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ControlChars selects how control characters such as newlines & tabs in
+// values are handled
+type ControlChars int
+
+const (
+	// ControlCharsDefault escapes control characters in tables and leaves
+	// them as is for every other output
+	ControlCharsDefault ControlChars = iota
+	// ControlCharsRaw leaves control characters as is
+	ControlCharsRaw
+	// ControlCharsStrip removes control characters
+	ControlCharsStrip
+	// ControlCharsSpace replaces each control character with a space
+	ControlCharsSpace
+	// ControlCharsEscape replaces control characters with Go style escapes: \n
+	ControlCharsEscape
+	// ControlCharsReplace replaces each control character with U+FFFD: �
+	ControlCharsReplace
+)
+
+// controlChars returns the mode to use for the current output
+func (o *options) controlChars() ControlChars {
+	if o.csv && o.csvControlCharMode != ControlCharsDefault {
+		return o.csvControlCharMode
+	}
+	if o.controlCharMode != ControlCharsDefault {
+		return o.controlCharMode
+	}
+	if o.table {
+		return ControlCharsEscape
+	}
+	return ControlCharsRaw
+}
+
+// sanitize handles any control characters in value.  Newlines, including
+// CRLF as a single newline, are kept in wrapped & multi-line table columns,
+// which break the line on them, and with WithNewlineMarker.  ANSI
+// color (SGR) sequences such as ESC[31m are kept as a whole, or removed by
+// ControlCharsStrip, so pre-colored values still render in color.
+func sanitize(value string, wrap bool, o *options) string {
+	mode := o.controlChars()
+	if mode == ControlCharsRaw || strings.IndexFunc(value, unicode.IsControl) < 0 {
+		return value
+	}
+
+	keepNewline := o.newlineMarker != "" || (wrap && o.table)
+	var b strings.Builder
+	for i := 0; i < len(value); {
+		if n := sgrLen(value[i:]); n > 0 {
+			if mode != ControlCharsStrip {
+				b.WriteString(value[i : i+n])
+			}
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(value[i:])
+		i += size
+		if keepNewline && r == '\r' && strings.HasPrefix(value[i:], "\n") {
+			continue // CRLF is a newline like LF
+		}
+		if !unicode.IsControl(r) || (r == '\n' && keepNewline) {
+			b.WriteRune(r)
+			continue
+		}
+		switch mode {
+		case ControlCharsSpace:
+			b.WriteRune(' ')
+		case ControlCharsEscape:
+			b.WriteString(escapeControl(r))
+		case ControlCharsReplace:
+			b.WriteRune(unicode.ReplacementChar)
+		}
+	}
+	return b.String()
+}
+
+// sgrLen returns the length of the ANSI color sequence at the start of s
+// or 0 if there is none.  Other escape sequences, such as cursor movement,
+// are not passed through.
+func sgrLen(s string) int {
+	if n := csiLen(s); n > 0 && s[n-1] == 'm' {
+		return n
+	}
+	return 0
+}
+
+// escapeControl returns the escaped form of a control character
+func escapeControl(r rune) string {
+	switch r {
+	case '\n':
+		return `\n`
+	case '\r':
+		return `\r`
+	case '\t':
+		return `\t`
+	}
+	if r < 0x100 {
+		return fmt.Sprintf(`\x%02x`, r)
+	}
+	return fmt.Sprintf(`\u%04x`, r)
+}
@@ -0,0 +1,86 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	const value = "\x1b[31mred\x1b[0m\x07\u0085\r\n"
+	tests := []struct {
+		name   string
+		mode   ControlChars
+		marker string
+		value  string
+		want   string
+	}{
+		{"printable", ControlCharsDefault, "", "plain", "plain"},
+		{"escape", ControlCharsDefault, "", value, "\x1b[31mred\x1b[0m\\x07\\x85\\r\\n"},
+		{"raw", ControlCharsRaw, "", value, value},
+		{"strip", ControlCharsStrip, "", value, "red"},
+		{"space", ControlCharsSpace, "", value, "\x1b[31mred\x1b[0m    "},
+		{"replace", ControlCharsReplace, "", "a\tb", "a�b"},
+		{"newline marker", ControlCharsStrip, "␤", "a\r\nb\tc", "a\nbc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newOptions([]Option{WithControlChars(tt.mode), WithNewlineMarker(tt.marker)})
+			o.table = true
+			if got := sanitize(tt.value, false, o); got != tt.want {
+				t.Errorf("sanitize(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestControlChars(t *testing.T) {
+	rows := []TableStruct{colorRow{Name: "a\tb", Value: "c\nd"}}
+	tests := []struct {
+		name  string
+		opts  []Option
+		table string
+		csv   string
+	}{
+		{"default", nil, "Name | Value\n============\na\\tb | c\\nd \n", "a\tb,\"c\nd\"\n"},
+		{"space", []Option{WithControlChars(ControlCharsSpace)}, "Name | Value\n============\na b  | c d  \n", "a b,c d\n"},
+		{"csv only", []Option{WithCSVControlChars(ControlCharsStrip)}, "Name | Value\n============\na\\tb | c\\nd \n", "ab,cd\n"},
+		{
+			name:  "csv overrides",
+			opts:  []Option{WithControlChars(ControlCharsReplace), WithCSVControlChars(ControlCharsEscape)},
+			table: "Name | Value\n============\na�b  | c�d  \n",
+			csv:   "a\\tb,c\\nd\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var table, csv bytes.Buffer
+			outputs := []Output{{Format: FormatTable, Writer: &table}, {Format: FormatCSV, Writer: &csv}}
+			if err := RenderMulti(rows, nil, outputs, tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if table.String() != tt.table {
+				t.Errorf("RenderMulti(table) = %q, want %q", table.String(), tt.table)
+			}
+			if csv.String() != tt.csv {
+				t.Errorf("RenderMulti(csv) = %q, want %q", csv.String(), tt.csv)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// East Asian Wide & Fullwidth ranges and emoji which terminals render two
+// cells wide
+var wideRunes = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x1100, 0x115f, 1},
+		{0x231a, 0x231b, 1},
+		{0x2329, 0x232a, 1},
+		{0x23e9, 0x23ec, 1},
+		{0x23f0, 0x23f0, 1},
+		{0x23f3, 0x23f3, 1},
+		{0x25fd, 0x25fe, 1},
+		{0x2614, 0x2615, 1},
+		{0x2648, 0x2653, 1},
+		{0x267f, 0x267f, 1},
+		{0x2693, 0x2693, 1},
+		{0x26a1, 0x26a1, 1},
+		{0x26aa, 0x26ab, 1},
+		{0x26bd, 0x26be, 1},
+		{0x26c4, 0x26c5, 1},
+		{0x26ce, 0x26ce, 1},
+		{0x26d4, 0x26d4, 1},
+		{0x26ea, 0x26ea, 1},
+		{0x26f2, 0x26f3, 1},
+		{0x26f5, 0x26f5, 1},
+		{0x26fa, 0x26fa, 1},
+		{0x26fd, 0x26fd, 1},
+		{0x2705, 0x2705, 1},
+		{0x270a, 0x270b, 1},
+		{0x2728, 0x2728, 1},
+		{0x274c, 0x274c, 1},
+		{0x274e, 0x274e, 1},
+		{0x2753, 0x2755, 1},
+		{0x2757, 0x2757, 1},
+		{0x2795, 0x2797, 1},
+		{0x27b0, 0x27b0, 1},
+		{0x27bf, 0x27bf, 1},
+		{0x2b1b, 0x2b1c, 1},
+		{0x2b50, 0x2b50, 1},
+		{0x2b55, 0x2b55, 1},
+		{0x2e80, 0x303e, 1},
+		{0x3041, 0x33ff, 1},
+		{0x3400, 0x4dbf, 1},
+		{0x4e00, 0x9fff, 1},
+		{0xa000, 0xa4cf, 1},
+		{0xa960, 0xa97f, 1},
+		{0xac00, 0xd7a3, 1},
+		{0xf900, 0xfaff, 1},
+		{0xfe10, 0xfe19, 1},
+		{0xfe30, 0xfe6f, 1},
+		{0xff00, 0xff60, 1},
+		{0xffe0, 0xffe6, 1},
+	},
+	R32: []unicode.Range32{
+		{0x16fe0, 0x16fe4, 1},
+		{0x17000, 0x18cff, 1},
+		{0x1b000, 0x1b2ff, 1},
+		{0x1f004, 0x1f004, 1},
+		{0x1f0cf, 0x1f0cf, 1},
+		{0x1f18e, 0x1f18e, 1},
+		{0x1f191, 0x1f19a, 1},
+		{0x1f200, 0x1f202, 1},
+		{0x1f210, 0x1f23b, 1},
+		{0x1f240, 0x1f248, 1},
+		{0x1f250, 0x1f251, 1},
+		{0x1f260, 0x1f265, 1},
+		{0x1f300, 0x1f320, 1},
+		{0x1f32d, 0x1f335, 1},
+		{0x1f337, 0x1f37c, 1},
+		{0x1f37e, 0x1f393, 1},
+		{0x1f3a0, 0x1f3ca, 1},
+		{0x1f3cf, 0x1f3d3, 1},
+		{0x1f3e0, 0x1f3f0, 1},
+		{0x1f3f4, 0x1f3f4, 1},
+		{0x1f3f8, 0x1f43e, 1},
+		{0x1f440, 0x1f440, 1},
+		{0x1f442, 0x1f4fc, 1},
+		{0x1f4ff, 0x1f53d, 1},
+		{0x1f54b, 0x1f54e, 1},
+		{0x1f550, 0x1f567, 1},
+		{0x1f57a, 0x1f57a, 1},
+		{0x1f595, 0x1f596, 1},
+		{0x1f5a4, 0x1f5a4, 1},
+		{0x1f5fb, 0x1f64f, 1},
+		{0x1f680, 0x1f6c5, 1},
+		{0x1f6cc, 0x1f6cc, 1},
+		{0x1f6d0, 0x1f6d2, 1},
+		{0x1f6d5, 0x1f6d7, 1},
+		{0x1f6eb, 0x1f6ec, 1},
+		{0x1f6f4, 0x1f6fc, 1},
+		{0x1f7e0, 0x1f7eb, 1},
+		{0x1f90c, 0x1f93a, 1},
+		{0x1f93c, 0x1f945, 1},
+		{0x1f947, 0x1f9ff, 1},
+		{0x1fa70, 0x1faff, 1},
+		{0x20000, 0x2fffd, 1},
+		{0x30000, 0x3fffd, 1},
+	},
+}
+
+// runeWidth returns the number of terminal cells r is displayed in:
+// 0 for control & combining characters, 2 for wide characters, else 1
+func runeWidth(r rune) int {
+	switch {
+	case r == 0x200d || (r >= 0xfe00 && r <= 0xfe0f):
+		return 0 // zero width joiner & variation selectors
+	case unicode.Is(unicode.Mn, r), unicode.Is(unicode.Me, r), unicode.Is(unicode.Cc, r):
+		return 0
+	case r >= 0x1100 && unicode.Is(wideRunes, r):
+		return 2
+	}
+	return 1
+}
+
+// csiLen returns the length of the ANSI escape sequence, such as the SGR
+// color ESC[31m, at the start of s or 0 if there is none
+func csiLen(s string) int {
+	if len(s) < 2 || s[0] != 0x1b || s[1] != '[' {
+		return 0
+	}
+	for i := 2; i < len(s); i++ {
+		switch {
+		case s[i] >= 0x20 && s[i] <= 0x3f:
+			continue // parameter & intermediate bytes
+		case s[i] >= 0x40 && s[i] <= 0x7e:
+			return i + 1
+		}
+		return 0
+	}
+	return 0
+}
+
+// displayWidth returns the number of terminal cells s is displayed in,
+// ignoring ANSI escape sequences
+func displayWidth(s string) int {
+	width := 0
+	for i := 0; i < len(s); {
+		if n := csiLen(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		width += runeWidth(r)
+		i += size
+	}
+	return width
+}
+
+// cutWidth returns the index of s after the most characters which fit in
+// width cells.  Escape sequences are never split.
+func cutWidth(s string, width int) int {
+	used := 0
+	for i := 0; i < len(s); {
+		if n := csiLen(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if used+runeWidth(r) > width {
+			return i
+		}
+		used += runeWidth(r)
+		i += size
+	}
+	return len(s)
+}
+
+// escapes returns the ANSI escape sequences in s
+func escapes(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if n := csiLen(s[i:]); n > 0 {
+			b.WriteString(s[i : i+n])
+			i += n
+			continue
+		}
+		i++
+	}
+	return b.String()
+}
+
+// headWidth returns the longest prefix of s which fits in width cells
+// followed by the escape sequences of the rest, so colors are still reset
+func headWidth(s string, width int) string {
+	i := cutWidth(s, width)
+	return s[:i] + escapes(s[i:])
+}
+
+// repeatWidth repeats s to fill width cells, cutting the last copy short
+// if s is more than one cell wide
+func repeatWidth(s string, width int) string {
+	n := displayWidth(s)
+	if n == 0 || width <= 0 {
+		return ""
+	}
+	return headWidth(strings.Repeat(s, (width+n-1)/n), width)
+}
+
+// tailWidth returns the longest suffix of s which fits in width cells
+// preceded by the escape sequences of the rest, so colors still apply
+func tailWidth(s string, width int) string {
+	// index of each character which is not part of an escape sequence
+	starts := []int{}
+	for i := 0; i < len(s); {
+		if n := csiLen(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		starts = append(starts, i)
+		i += size
+	}
+
+	start, used := len(s), 0
+	for j := len(starts) - 1; j >= 0; j-- {
+		r, _ := utf8.DecodeRuneInString(s[starts[j]:])
+		if used+runeWidth(r) > width {
+			break
+		}
+		used += runeWidth(r)
+		start = starts[j]
+	}
+	return escapes(s[:start]) + s[start:]
+}
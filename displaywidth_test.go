@@ -0,0 +1,241 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type colorRow struct {
+	Name  string `header:"Name"`
+	Value string `header:"Value"`
+}
+
+func (r colorRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestDisplayWidthANSI(t *testing.T) {
+	tests := []struct {
+		value string
+		width int
+	}{
+		{"red", 3},
+		{"\x1b[31mred\x1b[0m", 3},
+		{"\x1b[1;38;5;208mbold\x1b[0m", 4},
+		{"名前", 4},
+		{"\x1b[32m名前\x1b[0m", 4},
+	}
+	for _, tt := range tests {
+		if got := displayWidth(tt.value); got != tt.width {
+			t.Errorf("displayWidth(%q) = %d, want %d", tt.value, got, tt.width)
+		}
+	}
+}
+
+func TestTruncateANSI(t *testing.T) {
+	value := "\x1b[1mlonger bold value\x1b[0m"
+	tests := []struct {
+		mode Truncate
+		want string
+	}{
+		{TruncateEnd, "\x1b[1mlonger b\x1b[0m…"},
+		{TruncateStart, "…\x1b[1mld value\x1b[0m"},
+		{TruncateMiddle, "\x1b[1mlong\x1b[0m…\x1b[1malue\x1b[0m"},
+	}
+	for _, tt := range tests {
+		if got := truncateEllipsis(value, 9, "…", tt.mode); got != tt.want {
+			t.Errorf("truncateEllipsis(%d) = %q, want %q", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateTableANSI(t *testing.T) {
+	rows := []TableStruct{
+		colorRow{Name: "\x1b[31mred\x1b[0m", Value: "x"},
+		colorRow{Name: "plain text", Value: "\x1b[1mlonger bold value\x1b[0m"},
+	}
+	got, err := GenerateTableString(rows, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Name       | Value            \n" +
+		"==============================\n" +
+		"\x1b[31mred\x1b[0m        | x                \n" +
+		"plain text | \x1b[1mlonger bold value\x1b[0m\n"
+	if got != want {
+		t.Errorf("GenerateTableString() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestSanitizeANSI(t *testing.T) {
+	o := newOptions(nil)
+	o.table = true
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"\x1b[31mred\x1b[0m", "\x1b[31mred\x1b[0m"},
+		{"a\x1b[2Jb", `a\x1b[2Jb`},
+		{"bell\x07", `bell\x07`},
+		{"\x1b[31", `\x1b[31`},
+	}
+	for _, tt := range tests {
+		if got := sanitize(tt.value, false, o); got != tt.want {
+			t.Errorf("sanitize(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestDisplayWidth(t *testing.T) {
+	tests := []struct {
+		value string
+		width int
+	}{
+		{"abc", 3},
+		{"名前", 4},
+		{"😀👍", 4},
+		{"café", 4},
+		{"cafe\u0301", 4}, // combining acute accent
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := displayWidth(tt.value); got != tt.width {
+			t.Errorf("displayWidth(%q) = %d, want %d", tt.value, got, tt.width)
+		}
+	}
+}
+
+func TestGenerateTableWide(t *testing.T) {
+	rows := []TableStruct{
+		colorRow{Name: "名前", Value: "x"},
+		colorRow{Name: "😀👍", Value: "y"},
+		colorRow{Name: "café", Value: "z"},
+		colorRow{Name: "cafe\u0301", Value: "u"},
+		colorRow{Name: "été", Value: "w"},
+		colorRow{Name: "abc", Value: "v"},
+	}
+	got, err := GenerateTableString(rows, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Name | Value\n============\n名前 | x    \n😀👍 | y    \ncafé | z    \ncafe\u0301 | u    \nété  | w    \nabc  | v    \n"
+	if got != want {
+		t.Errorf("GenerateTableString() = %q, want %q", got, want)
+	}
+
+	// the separators line up on screen
+	for _, line := range strings.Split(strings.TrimSuffix(got, "\n"), "\n")[2:] {
+		if pipe := displayWidth(line[:strings.Index(line, "|")]); pipe != 5 {
+			t.Errorf("separator of %q at %d, want 5", line, pipe)
+		}
+		if width := displayWidth(line); width != 12 {
+			t.Errorf("width of %q = %d, want 12", line, width)
+		}
+	}
+}
+
+func TestMaxWidthANSI(t *testing.T) {
+	rows := []TableStruct{
+		colorRow{Name: "\x1b[31mred\x1b[0m", Value: "\x1b[1mlonger bold value here\x1b[0m"},
+		colorRow{Name: "plain", Value: "x"},
+	}
+	got, err := GenerateTableString(rows, nil, WithMaxColumnWidth(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Name  | Value     \n" +
+		"==================\n" +
+		"\x1b[31mred\x1b[0m   | \x1b[1mlonger bo\x1b[0m…\n" +
+		"plain | x         \n"
+	if got != want {
+		t.Errorf("GenerateTableString() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestWrapANSI(t *testing.T) {
+	lines := wrapCell("\x1b[32mthe quick brown fox\x1b[0m", 10)
+	if want := []string{"\x1b[32mthe quick", "brown fox\x1b[0m"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("wrapCell() = %q, want %q", lines, want)
+	}
+
+	rows := []TableStruct{
+		colorRow{Name: "\x1b[31mred\x1b[0m", Value: "\x1b[1mlonger bold value here\x1b[0m"},
+		colorRow{Name: "plain", Value: "x"},
+	}
+	got, err := GenerateTableString(rows, nil, WithWrap(), WithMaxColumnWidth(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, seq := range []string{"\x1b[31m", "\x1b[1m", "\x1b[0m"} {
+		if !strings.Contains(got, seq) {
+			t.Errorf("GenerateTableString() = %q, missing %q", got, seq)
+		}
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(got, "\n"), "\n") {
+		if width := displayWidth(line); width != 18 {
+			t.Errorf("width of %q = %d, want 18", line, width)
+		}
+	}
+}
+
+func TestRepeatWidth(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"=", 5, "====="},
+		{"=-", 5, "=-=-="},
+		{"=-", 4, "=-=-"},
+		{"─", 3, "───"},
+		{"中", 3, "中"},
+		{"", 3, ""},
+		{"=", 0, ""},
+	}
+	for _, tt := range tests {
+		if got := repeatWidth(tt.s, tt.width); got != tt.want {
+			t.Errorf("repeatWidth(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestMultiCharRules(t *testing.T) {
+	rows := []TableStruct{colorRow{Name: "a", Value: "b"}}
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{"header rule", []Option{WithHeaderRule("=-")}, "Name | Value\n=-=-=-=-=-=-\na    | b    \n"},
+		{"title underline", []Option{WithTitle("Hosts"), WithTitleUnderline("~-")}, "   Hosts\n   ~-~-~\nName | Value\n============\na    | b    \n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateTableString(rows, nil, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("GenerateTableString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type enumKey struct {
+	t     reflect.Type
+	field string
+}
+
+var (
+	enumLock sync.RWMutex
+	enums    = map[enumKey]map[int64]string{}
+)
+
+// RegisterEnum maps the integer values of field in the struct type t to
+// labels.  Values without a label are rendered as numbers.  Takes
+// precedence over the enum tag option: `header:"Status,enum=0:ok;1:warn"`
+func RegisterEnum(t reflect.Type, field string, labels map[int64]string) {
+	enumLock.Lock()
+	defer enumLock.Unlock()
+
+	copied := make(map[int64]string, len(labels))
+	for k, v := range labels {
+		copied[k] = v
+	}
+	enums[enumKey{t: t, field: field}] = copied
+	resetTypeInfo()
+}
+
+// lookupEnum returns the registered labels for the field or nil
+func lookupEnum(t reflect.Type, field string) map[int64]string {
+	enumLock.RLock()
+	defer enumLock.RUnlock()
+
+	return enums[enumKey{t: t, field: field}]
+}
+
+// parseEnum parses the enum tag option: 0:ok;1:warn;2:crit
+func parseEnum(value string) (map[int64]string, error) {
+	labels := map[int64]string{}
+	for _, pair := range strings.Split(value, ";") {
+		kv := strings.SplitN(pair, ":", 2)
+		if len(kv) != 2 {
+			return labels, fmt.Errorf("expected value:label, got '%s'", pair)
+		}
+		n, err := strconv.ParseInt(kv[0], 10, 64)
+		if err != nil {
+			return labels, fmt.Errorf("invalid value '%s'", kv[0])
+		}
+		labels[n] = kv[1]
+	}
+	return labels, nil
+}
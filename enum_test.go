@@ -0,0 +1,135 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"reflect"
+	"testing"
+)
+
+type alertRow struct {
+	Status int   `header:"Status,enum=0:ok;1:warn;2:crit"`
+	Level  uint8 `header:"Level,enum=1:low;2:high"`
+	Code   int   `header:"Code"`
+}
+
+func (r alertRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+type badEnumRow struct {
+	Status int `header:"Status,enum=0:ok;x:warn"`
+}
+
+func (r badEnumRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+// withEnum registers labels for the test and removes them afterwards
+func withEnum(t *testing.T, rowType reflect.Type, field string, labels map[int64]string) {
+	RegisterEnum(rowType, field, labels)
+	t.Cleanup(func() {
+		enumLock.Lock()
+		delete(enums, enumKey{t: rowType, field: field})
+		enumLock.Unlock()
+		resetTypeInfo()
+	})
+}
+
+func TestEnum(t *testing.T) {
+	tests := []struct {
+		name       string
+		row        alertRow
+		registered map[string]map[int64]string
+		want       map[string]string
+	}{
+		{
+			name: "tag",
+			row:  alertRow{Status: 2, Level: 1, Code: 2},
+			want: map[string]string{"Status": "crit", "Level": "low", "Code": "2"},
+		},
+		{
+			name: "no label",
+			row:  alertRow{Status: 7, Level: 0},
+			want: map[string]string{"Status": "7", "Level": "0", "Code": "0"},
+		},
+		{
+			name:       "registered",
+			row:        alertRow{Status: 1, Level: 2, Code: 404},
+			registered: map[string]map[int64]string{"Status": {1: "WARN"}, "Code": {404: "not found"}},
+			want:       map[string]string{"Status": "WARN", "Level": "high", "Code": "not found"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for field, labels := range tt.registered {
+				withEnum(t, reflect.TypeOf(alertRow{}), field, labels)
+			}
+			got, _, err := TableRow(tt.row)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TableRow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnumTable(t *testing.T) {
+	rows := []TableStruct{alertRow{Status: 0, Level: 2, Code: 1}, alertRow{Status: 2, Level: 1, Code: 10}}
+	got, err := GenerateTableString(rows, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// enums are left aligned like text
+	want := "Status | Level | Code\n=====================\nok     | high  |    1\ncrit   | low   |   10\n"
+	if got != want {
+		t.Errorf("GenerateTableString() = %q, want %q", got, want)
+	}
+}
+
+func TestParseEnum(t *testing.T) {
+	tests := []struct {
+		value string
+		want  map[int64]string
+		err   string
+	}{
+		{"0:ok;1:warn", map[int64]string{0: "ok", 1: "warn"}, ""},
+		{"-1:unknown;1:a:b", map[int64]string{-1: "unknown", 1: "a:b"}, ""},
+		{"0:ok;1", nil, "expected value:label, got '1'"},
+		{"x:ok", nil, "invalid value 'x'"},
+	}
+	for _, tt := range tests {
+		got, err := parseEnum(tt.value)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("parseEnum(%q) error = %v, want %q", tt.value, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseEnum(%q) = %v, %v, want %v", tt.value, got, err, tt.want)
+		}
+	}
+
+	_, _, err := TableRow(badEnumRow{})
+	if want := "Invalid enum for field 'Status' in badEnumRow: invalid value 'x'"; err == nil || err.Error() != want {
+		t.Errorf("TableRow() error = %v, want %q", err, want)
+	}
+}
@@ -0,0 +1,62 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"strings"
+)
+
+// escapeTable returns a copy of t with each header & value escaped for
+// the plain text table
+func escapeTable(t *tableData, o *options) *tableData {
+	if !o.escapeSeparator && o.newlineMarker == "" {
+		return t
+	}
+
+	ret := *t
+	ret.headers = escapeRow(t.headers, o)
+	if t.footer != nil {
+		ret.footer = escapeRow(t.footer, o)
+	}
+	ret.rows = make([][]string, len(t.rows))
+	for i, row := range t.rows {
+		ret.rows[i] = escapeRow(row, o)
+	}
+	return &ret
+}
+
+func escapeRow(values []string, o *options) []string {
+	ret := make([]string, len(values))
+	for i, value := range values {
+		ret[i] = escapeCell(value, o)
+	}
+	return ret
+}
+
+// escapeCell escapes the column separator & replaces newlines so a
+// single value can not break the layout of the table
+func escapeCell(value string, o *options) string {
+	if o.escapeSeparator {
+		value = strings.ReplaceAll(value, "|", "\\|")
+	}
+	if o.newlineMarker != "" {
+		value = strings.ReplaceAll(value, "\r\n", o.newlineMarker)
+		value = strings.ReplaceAll(value, "\n", o.newlineMarker)
+	}
+	return value
+}
@@ -0,0 +1,66 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEscapeCell(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		opts  []Option
+		want  string
+	}{
+		{"unchanged", "a|b\nc", nil, "a|b\nc"},
+		{"separator", "a|b||c", []Option{WithEscapeSeparator()}, "a\\|b\\|\\|c"},
+		{"newline", "a\r\nb\nc", []Option{WithNewlineMarker("␤")}, "a␤b␤c"},
+		{"both", "a|b\nc", []Option{WithEscapeSeparator(), WithNewlineMarker("\\n")}, "a\\|b\\nc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeCell(tt.value, newOptions(tt.opts)); got != tt.want {
+				t.Errorf("escapeCell(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeSeparator(t *testing.T) {
+	rows := []TableStruct{colorRow{Name: "a|b", Value: "c"}}
+	opts := []Option{WithEscapeSeparator(), WithHeaderOverrides(map[string]string{"Value": "In|Out"})}
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{FormatTable, "Name | In\\|Out\n==============\na\\|b | c      \n"},
+		// only the plain text table is escaped
+		{FormatCSV, "a|b,c\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := RenderMulti(rows, nil, []Output{{Format: tt.format, Writer: &buf}}, opts...); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("RenderMulti(%d) = %q, want %q", tt.format, buf.String(), tt.want)
+		}
+	}
+}
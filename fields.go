@@ -0,0 +1,258 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ResolveFields returns the struct field names of the columns the list of
+// fields selects for the struct (or pointer to struct) v.  "*" selects
+// every field not otherwise listed and "-Field" excludes a field.
+func ResolveFields(v interface{}, fields []string, opts ...Option) ([]string, error) {
+	o := newOptions(opts)
+	fhs, err := GetHeaders(v, opts...)
+	if err != nil {
+		return []string{}, err
+	}
+	headers := make(map[string]string, len(fhs)+len(o.derived))
+	for _, fh := range fhs {
+		headers[fh.Field] = fh.Header
+	}
+	for _, d := range o.derived {
+		headers[d.name] = d.header
+	}
+	return resolveFields(fields, reflect.Indirect(reflect.ValueOf(v)).Type(), headers, o)
+}
+
+// resolveFields maps the list of fields requested by the caller to the
+// field names in headers.  rowType is the struct type of the rows, if known.
+// Fields are always matched ignoring case unless the exact name exists,
+// which is an error if several fields only differ by case.  Fields may
+// also be selected by their header, but field names take precedence.
+// No fields or "*" selects every field sorted by the order tag option
+// followed by any derived columns.  "-Field" excludes a field.  Finally
+// the WithColumnFilter predicate removes any columns it rejects.
+func resolveFields(fields []string, rowType reflect.Type, headers map[string]string, o *options) ([]string, error) {
+	if len(fields) == 0 {
+		fields = []string{"*"}
+	}
+
+	selected := map[string]bool{}
+	excluded := map[string]bool{}
+	ret := []string{}
+	wildcard := -1
+	for _, field := range fields {
+		if field == "*" {
+			if wildcard >= 0 {
+				return ret, fmt.Errorf("Invalid fields: '*' may only be used once")
+			}
+			wildcard = len(ret)
+			continue
+		}
+		if strings.HasPrefix(field, "-") {
+			name, err := resolveField(strings.TrimPrefix(field, "-"), rowType, headers, o)
+			if err != nil {
+				return ret, err
+			}
+			excluded[name] = true
+			continue
+		}
+		name, err := resolveField(field, rowType, headers, o)
+		if err != nil {
+			return ret, err
+		}
+		selected[name] = true
+		ret = append(ret, name)
+	}
+
+	for name := range excluded {
+		if selected[name] {
+			return ret, fmt.Errorf("Field '%s' is both selected and excluded", name)
+		}
+	}
+
+	if wildcard < 0 {
+		return o.filterColumns(ret), nil
+	}
+	rest := []string{}
+	if rowType == nil {
+		// without a struct type there is no field order to follow
+		rest = sortedKeys(headers)
+	} else {
+		ordered, err := orderedFields(rowType, o)
+		if err != nil {
+			return ret, err
+		}
+		for _, f := range ordered {
+			rest = append(rest, f.Name)
+		}
+		for _, d := range o.derived {
+			rest = append(rest, d.name)
+		}
+	}
+	expanded := []string{}
+	for _, name := range rest {
+		if !selected[name] && !excluded[name] {
+			expanded = append(expanded, name)
+		}
+	}
+	return o.filterColumns(append(ret[:wildcard], append(expanded, ret[wildcard:]...)...)), nil
+}
+
+// filterColumns returns the fields selected by the WithColumnFilter predicate
+func (o *options) filterColumns(fields []string) []string {
+	if o.columnFilter == nil {
+		return fields
+	}
+	ret := []string{}
+	for _, field := range fields {
+		if o.columnFilter(field) {
+			ret = append(ret, field)
+		}
+	}
+	return ret
+}
+
+// resolveField returns the field name in headers selected by field
+func resolveField(field string, rowType reflect.Type, headers map[string]string, o *options) (string, error) {
+	if _, ok := headers[field]; ok {
+		return field, nil
+	}
+	if rowType != nil {
+		if f, ok := rowType.FieldByName(field); ok {
+			if f.PkgPath != "" {
+				return field, fmt.Errorf("Unable to select unexported field '%s' in %s", field, rowType.Name())
+			}
+			if o.isExcluded(f) {
+				return field, fmt.Errorf("Unable to select excluded field '%s' in %s", field, rowType.Name())
+			}
+		}
+	}
+	name, err := matchField(field, headers)
+	if err != nil || name != "" {
+		return name, err
+	}
+	name, err = matchHeader(field, headers)
+	if err != nil || name != "" {
+		return name, err
+	}
+	if rowType == nil || o.allowMissing {
+		return field, nil
+	}
+	if suggestion := suggestField(field, headers); suggestion != "" {
+		return field, fmt.Errorf("Invalid field '%s' in %s, did you mean '%s'?", field, rowType.Name(), suggestion)
+	}
+	return field, fmt.Errorf("Invalid field '%s' in %s, valid fields: %s", field, rowType.Name(), strings.Join(sortedKeys(headers), ", "))
+}
+
+// suggestField returns the field name or header in headers closest to
+// field, if any is close enough to be a typo
+func suggestField(field string, headers map[string]string) string {
+	best := ""
+	bestDist := 3 // anything further away is not a typo
+	for _, name := range sortedKeys(headers) {
+		for _, candidate := range []string{name, headers[name]} {
+			dist := editDistance(strings.ToLower(field), strings.ToLower(candidate))
+			if dist < bestDist {
+				best, bestDist = candidate, dist
+			}
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a & b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j-1] + cost
+			if prev[j]+1 < cur[j] {
+				cur[j] = prev[j] + 1
+			}
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// matchField returns the field name in headers which matches field ignoring
+// case or "" if there is none
+func matchField(field string, headers map[string]string) (string, error) {
+	matches := []string{}
+	for name := range headers {
+		if strings.EqualFold(name, field) {
+			matches = append(matches, name)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", nil
+	case 1:
+		return matches[0], nil
+	}
+	sort.Strings(matches)
+	return "", fmt.Errorf("Ambiguous field '%s' matches: %s", field, strings.Join(matches, ", "))
+}
+
+// matchHeader returns the field name in headers whose header matches
+// header ignoring case & surrounding whitespace or "" if there is none
+func matchHeader(header string, headers map[string]string) (string, error) {
+	header = strings.TrimSpace(header)
+	matches := []string{}
+	for name, h := range headers {
+		if strings.EqualFold(strings.TrimSpace(h), header) {
+			matches = append(matches, name)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", nil
+	case 1:
+		return matches[0], nil
+	}
+	sort.Strings(matches)
+	return "", fmt.Errorf("Ambiguous header '%s' matches fields: %s", header, strings.Join(matches, ", "))
+}
+
+// sortedKeys returns the keys of m in sorted order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
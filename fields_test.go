@@ -0,0 +1,383 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"bytes"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestFieldOrder(t *testing.T) {
+	tests := []struct {
+		format  Format
+		natural string
+		swapped string
+	}{
+		{
+			FormatTable,
+			"Name | Value\n============\na    | 1    \nb    | 2    \n",
+			"Value | Name\n============\n1     | a   \n2     | b   \n",
+		},
+		{
+			FormatCSV,
+			"Name,Value\na,1\nb,2\n",
+			"Value,Name\n1,a\n2,b\n",
+		},
+		{
+			FormatVertical,
+			"*** row 1 ***\n Name: a\nValue: 1\n*** row 2 ***\n Name: b\nValue: 2\n",
+			"*** row 1 ***\nValue: 1\n Name: a\n*** row 2 ***\nValue: 2\n Name: b\n",
+		},
+		{
+			FormatTransposed,
+			"Name  | a | b\nValue | 1 | 2\n",
+			"Value | 1 | 2\nName  | a | b\n",
+		},
+		{
+			FormatYAML,
+			"- Name: a\n  Value: 1\n- Name: b\n  Value: 2\n",
+			"- Value: 1\n  Name: a\n- Value: 2\n  Name: b\n",
+		},
+		{
+			FormatLaTeX,
+			"\\begin{tabular}{ll}\n\\hline\nName & Value \\\\\n\\hline\na & 1 \\\\\nb & 2 \\\\\n\\hline\n\\end{tabular}\n",
+			"\\begin{tabular}{ll}\n\\hline\nValue & Name \\\\\n\\hline\n1 & a \\\\\n2 & b \\\\\n\\hline\n\\end{tabular}\n",
+		},
+		{
+			FormatConfluence,
+			"||Name||Value||\n|a|1|\n|b|2|\n",
+			"||Value||Name||\n|1|a|\n|2|b|\n",
+		},
+		{
+			FormatOrg,
+			"| Name | Value |\n|------+-------|\n| a    | 1     |\n| b    | 2     |\n",
+			"| Value | Name |\n|-------+------|\n| 1     | a    |\n| 2     | b    |\n",
+		},
+	}
+	for _, tt := range tests {
+		for _, order := range []struct {
+			fields []string
+			want   string
+		}{
+			{[]string{"Name", "Value"}, tt.natural},
+			{[]string{"Value", "Name"}, tt.swapped},
+		} {
+			var buf bytes.Buffer
+			err := RenderMulti(multiRows(), order.fields, []Output{{Format: tt.format, Writer: &buf}}, WithCSVHeader())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != order.want {
+				t.Errorf("RenderMulti(%d, %v) = %q, want %q", tt.format, order.fields, buf.String(), order.want)
+			}
+		}
+	}
+}
+
+type privateRow struct {
+	mu     sync.Mutex
+	Name   string `header:"Name"`
+	cached string
+	Count  int `header:"Count"`
+}
+
+func (r *privateRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestUnexportedFields(t *testing.T) {
+	row := &privateRow{Name: "a", cached: "x", Count: 2}
+	values, headers, err := TableRow(row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"Name": "a", "Count": "2"}; !reflect.DeepEqual(values, want) {
+		t.Errorf("TableRow() values = %v, want %v", values, want)
+	}
+	if want := map[string]string{"Name": "Name", "Count": "Count"}; !reflect.DeepEqual(headers, want) {
+		t.Errorf("TableRow() headers = %v, want %v", headers, want)
+	}
+
+	tests := []struct {
+		fields []string
+		want   string
+		err    string
+	}{
+		{nil, "Name | Count\n============\na    |     2\n", ""},
+		{[]string{"Name", "cached"}, "", "Unable to select unexported field 'cached' in privateRow"},
+		{[]string{"mu"}, "", "Unable to select unexported field 'mu' in privateRow"},
+	}
+	for _, tt := range tests {
+		got, err := GenerateTableString([]TableStruct{row}, tt.fields)
+		if (err == nil && tt.err != "") || (err != nil && err.Error() != tt.err) {
+			t.Errorf("GenerateTableString(%v) error = %v, want %q", tt.fields, err, tt.err)
+		}
+		if got != tt.want {
+			t.Errorf("GenerateTableString(%v) = %q, want %q", tt.fields, got, tt.want)
+		}
+	}
+}
+
+type excludedRow struct {
+	Name   string `header:"Name"`
+	Secret string `header:"-"`
+}
+
+func (r excludedRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestExcludedFields(t *testing.T) {
+	row := excludedRow{Name: "a", Secret: "s"}
+	values, headers, err := TableRow(row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := headers["Secret"]; ok {
+		t.Errorf("TableRow() headers = %v, want no Secret", headers)
+	}
+	if _, ok := values["Secret"]; ok {
+		t.Errorf("TableRow() values = %v, want no Secret", values)
+	}
+
+	if _, err := GetHeaderTag(reflect.ValueOf(row), "Secret"); err == nil {
+		t.Errorf("GetHeaderTag(Secret) want error")
+	}
+
+	_, err = GenerateTableString([]TableStruct{row}, []string{"Name", "Secret"})
+	if want := "Unable to select excluded field 'Secret' in excludedRow"; err == nil || err.Error() != want {
+		t.Errorf("GenerateTableString() error = %v, want %q", err, want)
+	}
+}
+
+type orderedRow struct {
+	A string `header:"A"`
+	B string `header:"B,order=2"`
+	C string `header:"C,order=1"`
+	D string `header:"D"`
+	E string `header:"E,order=1"`
+}
+
+func (r orderedRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestOrderTag(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []string
+		want   []string
+	}{
+		{"nil", nil, []string{"C", "E", "B", "A", "D"}},
+		{"wildcard", []string{"*"}, []string{"C", "E", "B", "A", "D"}},
+		{"explicit", []string{"D", "A"}, []string{"D", "A"}},
+		{"exclude", []string{"*", "-C"}, []string{"E", "B", "A", "D"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveFields(orderedRow{}, tt.fields)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ResolveFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	headers, err := GetHeaders(orderedRow{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []FieldHeader{{"C", "C"}, {"E", "E"}, {"B", "B"}, {"A", "A"}, {"D", "D"}}
+	if !reflect.DeepEqual(headers, want) {
+		t.Errorf("GetHeaders() = %v, want %v", headers, want)
+	}
+}
+
+type layoutRow struct {
+	Host   string `header:"Host"`
+	secret string
+	Port   int    `header:"Port"`
+	Token  string `header:"-"`
+}
+
+func (r layoutRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestDefaultFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		rows   []TableStruct
+		fields []string
+		want   string
+	}{
+		{
+			name: "nil",
+			rows: []TableStruct{layoutRow{Host: "a", secret: "s", Port: 80, Token: "t"}},
+			want: "Host | Port\n===========\na    |   80\n",
+		},
+		{
+			name:   "empty",
+			rows:   []TableStruct{layoutRow{Host: "a", secret: "s", Port: 80, Token: "t"}},
+			fields: []string{},
+			want:   "Host | Port\n===========\na    |   80\n",
+		},
+		{
+			name: "first row layout",
+			rows: []TableStruct{colorRow{Name: "a", Value: "1"}, layoutRow{Host: "b", Port: 80}},
+			want: "Name | Value\n============\na    | 1    \n     |      \n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateTableString(tt.rows, tt.fields)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("GenerateTableString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnknownFields(t *testing.T) {
+	rows := []TableStruct{layoutRow{Host: "a", Port: 80}}
+	tests := []struct {
+		name   string
+		fields []string
+		opts   []Option
+		want   string
+		err    string
+	}{
+		{"typo", []string{"Host", "Prot"}, nil, "", "Invalid field 'Prot' in layoutRow, did you mean 'Port'?"},
+		{"unknown", []string{"Zzzzzz"}, nil, "", "Invalid field 'Zzzzzz' in layoutRow, valid fields: Host, Port"},
+		{"nested path", []string{"Host.Name"}, nil, "", "Invalid field 'Host.Name' in layoutRow, valid fields: Host, Port"},
+		{"case", []string{"host"}, nil, "Host\n====\na   \n", ""},
+		{"lenient", []string{"Host", "Prot"}, []Option{WithAllowMissingFields()}, "Host | \n=======\na    | \n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateTableString(rows, tt.fields, tt.opts...)
+			if (err == nil && tt.err != "") || (err != nil && err.Error() != tt.err) {
+				t.Errorf("GenerateTableString() error = %v, want %q", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("GenerateTableString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type headerSelectRow struct {
+	Hostname string `header:"Host"`
+	Host     string `header:"Server"`
+	Addr     string `header:"IP Address"`
+	Primary  string `header:"Zone"`
+	Backup   string `header:"zone"`
+}
+
+func (r headerSelectRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestSelectByHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []string
+		want   []string
+		err    string
+	}{
+		{"header", []string{"IP Address", "Server"}, []string{"Addr", "Host"}, ""},
+		{"ignoring case & whitespace", []string{" ip address "}, []string{"Addr"}, ""},
+		{"field name wins", []string{"Host"}, []string{"Host"}, ""},
+		{"excluded by header", []string{"*", "-IP Address", "-Primary", "-Backup"}, []string{"Hostname", "Host"}, ""},
+		{"ambiguous", []string{"ZONE"}, nil, "Ambiguous header 'ZONE' matches fields: Backup, Primary"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveFields(headerSelectRow{}, tt.fields)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("ResolveFields() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ResolveFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	row := headerSelectRow{Hostname: "a", Addr: "10.0.0.1"}
+	got, err := GenerateTableString([]TableStruct{row}, []string{"host", "ip address"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Server | IP Address\n===================\n       | 10.0.0.1  \n"; got != want {
+		t.Errorf("GenerateTableString() = %q, want %q", got, want)
+	}
+}
+
+func TestColumnFilter(t *testing.T) {
+	verbose := false
+	filter := WithColumnFilter(func(field string) bool {
+		return verbose || field != "Addr"
+	})
+	tests := []struct {
+		name    string
+		verbose bool
+		fields  []string
+		want    []string
+	}{
+		{"hidden", false, nil, []string{"Hostname", "Host", "Primary", "Backup"}},
+		{"shown", true, nil, []string{"Hostname", "Host", "Addr", "Primary", "Backup"}},
+		{"hidden when selected", false, []string{"Addr", "Host"}, []string{"Host"}},
+		{"selected by header", false, []string{"IP Address", "Server"}, []string{"Host"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verbose = tt.verbose
+			got, err := ResolveFields(headerSelectRow{}, tt.fields, filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ResolveFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	verbose = false
+	row := headerSelectRow{Hostname: "a", Addr: "10.0.0.1"}
+	var buf bytes.Buffer
+	err := RenderMulti([]TableStruct{row}, []string{"Hostname", "Addr"}, []Output{{Format: FormatCSV, Writer: &buf}}, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "a\n" {
+		t.Errorf("RenderMulti() = %q, want %q", buf.String(), "a\n")
+	}
+}
@@ -0,0 +1,124 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"database/sql"
+	"net"
+	"reflect"
+	"sync"
+)
+
+// TypeFormatter converts a field value of a registered type into a string
+type TypeFormatter func(v reflect.Value) (string, error)
+
+type typeFormatter struct {
+	t  reflect.Type
+	fn TypeFormatter
+}
+
+var (
+	formatterLock sync.RWMutex
+	formatters    = []typeFormatter{}
+
+	// formatters for well known types which are used unless the
+	// caller registers their own via RegisterTypeFormatter()
+	builtinFormatters = map[reflect.Type]TypeFormatter{
+		reflect.TypeOf(net.IP{}):           formatIP,
+		reflect.TypeOf(net.IPNet{}):        formatIPNet,
+		reflect.TypeOf(&net.IPNet{}):       formatIPNet,
+		reflect.TypeOf(net.HardwareAddr{}): formatHardwareAddr,
+	}
+
+	// database/sql Null types which wrap a single value with a Valid flag
+	sqlNullTypes = map[reflect.Type]bool{
+		reflect.TypeOf(sql.NullString{}):  true,
+		reflect.TypeOf(sql.NullInt64{}):   true,
+		reflect.TypeOf(sql.NullInt32{}):   true,
+		reflect.TypeOf(sql.NullFloat64{}): true,
+		reflect.TypeOf(sql.NullBool{}):    true,
+		reflect.TypeOf(sql.NullTime{}):    true,
+	}
+)
+
+// RegisterTypeFormatter registers fn to render any field whose type is t
+// or is assignable to t.  An exact type match is always preferred over an
+// assignable one and registering the same type again replaces the previous
+// formatter.  Safe to call from init() in multiple packages.
+func RegisterTypeFormatter(t reflect.Type, fn TypeFormatter) {
+	formatterLock.Lock()
+	defer formatterLock.Unlock()
+
+	for i, f := range formatters {
+		if f.t == t {
+			formatters[i].fn = fn
+			return
+		}
+	}
+	formatters = append(formatters, typeFormatter{t: t, fn: fn})
+}
+
+// lookupTypeFormatter returns the formatter for t or nil
+func lookupTypeFormatter(t reflect.Type) TypeFormatter {
+	formatterLock.RLock()
+	defer formatterLock.RUnlock()
+
+	for _, f := range formatters {
+		if f.t == t {
+			return f.fn
+		}
+	}
+	for _, f := range formatters {
+		if t.AssignableTo(f.t) {
+			return f.fn
+		}
+	}
+	return builtinFormatters[t]
+}
+
+// net.IP which is nil or not a valid IPv4/IPv6 address is blank
+func formatIP(v reflect.Value) (string, error) {
+	ip := net.IP(v.Bytes())
+	if ip.To16() == nil {
+		return "", nil
+	}
+	return ip.String(), nil
+}
+
+// net.IPNet or *net.IPNet in CIDR notation.  nil is blank
+func formatIPNet(v reflect.Value) (string, error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+	ipnet := net.IPNet{
+		IP:   net.IP(v.FieldByName("IP").Bytes()),
+		Mask: net.IPMask(v.FieldByName("Mask").Bytes()),
+	}
+	if ipnet.IP.To16() == nil {
+		return "", nil
+	}
+	return ipnet.String(), nil
+}
+
+// net.HardwareAddr in colon separated hex.  nil is blank
+func formatHardwareAddr(v reflect.Value) (string, error) {
+	return net.HardwareAddr(v.Bytes()).String(), nil
+}
@@ -0,0 +1,241 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type timeRow struct {
+	When time.Time `header:"When"`
+}
+
+func (r timeRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+type celsius float64
+
+type tempRow struct {
+	Temp celsius `header:"Temp"`
+}
+
+func (r tempRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+// withFormatters restores the registered formatters once the test is done
+func withFormatters(t *testing.T) {
+	formatterLock.Lock()
+	saved := append([]typeFormatter{}, formatters...)
+	formatterLock.Unlock()
+	t.Cleanup(func() {
+		formatterLock.Lock()
+		formatters = saved
+		formatterLock.Unlock()
+	})
+}
+
+func TestRegisterTypeFormatter(t *testing.T) {
+	when := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	tests := []struct {
+		name string
+		fn   TypeFormatter
+		want string
+	}{
+		{"default", nil, "2021-03-04T05:06:07Z"},
+		{"override", func(v reflect.Value) (string, error) {
+			return v.Interface().(time.Time).Format("Jan 2"), nil
+		}, "Mar 4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withFormatters(t)
+			if tt.fn != nil {
+				RegisterTypeFormatter(reflect.TypeOf(time.Time{}), tt.fn)
+			}
+			row, _, err := TableRow(timeRow{When: when})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if row["When"] != tt.want {
+				t.Errorf("When = %q, want %q", row["When"], tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterTypeFormatterReplace(t *testing.T) {
+	withFormatters(t)
+	celsiusType := reflect.TypeOf(celsius(0))
+	RegisterTypeFormatter(celsiusType, func(v reflect.Value) (string, error) {
+		return "first", nil
+	})
+	RegisterTypeFormatter(celsiusType, func(v reflect.Value) (string, error) {
+		return "second", nil
+	})
+	row, _, err := TableRow(tempRow{Temp: 21.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row["Temp"] != "second" {
+		t.Errorf("Temp = %q, want %q", row["Temp"], "second")
+	}
+}
+
+func TestRegisterTypeFormatterError(t *testing.T) {
+	withFormatters(t)
+	errCold := errors.New("too cold")
+	RegisterTypeFormatter(reflect.TypeOf(celsius(0)), func(v reflect.Value) (string, error) {
+		return "", errCold
+	})
+	_, err := GenerateTableString([]TableStruct{tempRow{Temp: -40}}, nil)
+	if err == nil || !strings.Contains(err.Error(), "'Temp'") || !strings.Contains(err.Error(), "too cold") {
+		t.Errorf("GenerateTableString() = %v, want error naming field 'Temp'", err)
+	}
+}
+
+func TestRegisterTypeFormatterAssignable(t *testing.T) {
+	withFormatters(t)
+	RegisterTypeFormatter(stringerType, func(v reflect.Value) (string, error) {
+		return "<" + v.Interface().(fmt.Stringer).String() + ">", nil
+	})
+	row, _, err := TableRow(stringerRow{Color: 1, Point: &point{}, Str: color(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row["Color"] != "<green>" {
+		t.Errorf("Color = %q, want %q", row["Color"], "<green>")
+	}
+}
+
+type sqlRow struct {
+	String sql.NullString `header:"String"`
+	Int    sql.NullInt64  `header:"Int"`
+	Bool   sql.NullBool   `header:"Bool"`
+	Time   sql.NullTime   `header:"Time"`
+}
+
+func (r sqlRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestSQLNullTypes(t *testing.T) {
+	when := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	tests := []struct {
+		name string
+		row  sqlRow
+		opts []Option
+		want map[string]string
+	}{
+		{
+			name: "valid",
+			row: sqlRow{
+				String: sql.NullString{String: "text", Valid: true},
+				Int:    sql.NullInt64{Int64: 42, Valid: true},
+				Bool:   sql.NullBool{Bool: false, Valid: true},
+				Time:   sql.NullTime{Time: when, Valid: true},
+			},
+			want: map[string]string{"String": "text", "Int": "42", "Bool": "false", "Time": "2021-03-04T05:06:07Z"},
+		},
+		{
+			name: "invalid",
+			row: sqlRow{
+				String: sql.NullString{String: "ignored"},
+				Int:    sql.NullInt64{Int64: 42},
+			},
+			want: map[string]string{"String": "", "Int": "", "Bool": "", "Time": ""},
+		},
+		{
+			name: "placeholder",
+			opts: []Option{WithNullPlaceholder("NULL")},
+			want: map[string]string{"String": "NULL", "Int": "NULL", "Bool": "NULL", "Time": "NULL"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row, _, err := TableRow(tt.row, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(row, tt.want) {
+				t.Errorf("TableRow() = %v, want %v", row, tt.want)
+			}
+		})
+	}
+}
+
+type netRow struct {
+	IP   net.IP           `header:"IP"`
+	Net  *net.IPNet       `header:"Net"`
+	Mask net.IPNet        `header:"Mask"`
+	MAC  net.HardwareAddr `header:"MAC"`
+}
+
+func (r netRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestNetTypes(t *testing.T) {
+	_, cidr, _ := net.ParseCIDR("10.0.0.0/8")
+	mac, _ := net.ParseMAC("00:11:22:33:44:55")
+	tests := []struct {
+		name string
+		row  netRow
+		want map[string]string
+	}{
+		{
+			name: "values",
+			row:  netRow{IP: net.ParseIP("192.168.1.1"), Net: cidr, Mask: *cidr, MAC: mac},
+			want: map[string]string{"IP": "192.168.1.1", "Net": "10.0.0.0/8", "Mask": "10.0.0.0/8", "MAC": "00:11:22:33:44:55"},
+		},
+		{
+			name: "ipv6",
+			row:  netRow{IP: net.ParseIP("2001:db8::1")},
+			want: map[string]string{"IP": "2001:db8::1", "Net": "", "Mask": "", "MAC": ""},
+		},
+		{
+			name: "nil",
+			row:  netRow{},
+			want: map[string]string{"IP": "", "Net": "", "Mask": "", "MAC": ""},
+		},
+		{
+			name: "invalid",
+			row:  netRow{IP: net.IP{1, 2, 3}},
+			want: map[string]string{"IP": "", "Net": "", "Mask": "", "MAC": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := TableRow(tt.row)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TableRow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -18,11 +18,20 @@ package gotable
  * along with this program.  If not, see <http://www.gnu.org/licenses/>.
  */
 import (
+	"bytes"
+	"crypto/sha256"
 	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 const (
@@ -34,153 +43,877 @@ type TableStruct interface {
 	GetHeader(string) (string, error)
 }
 
+// TableMarshaler is implemented by types which render their own rows
+// instead of being converted field by field via reflection.  fields are
+// the fields selected by the caller or nil for every field.  The headers
+// come from GetHeader, defaulting to the field name.
+type TableMarshaler interface {
+	TableStruct
+	MarshalTableRow(fields []string) (map[string]string, error)
+}
+
 // Returns a row and a mapping of struct field name to header names
-func TableRow(table TableStruct) (map[string]string, map[string]string, error) {
+func TableRow(table TableStruct, opts ...Option) (map[string]string, map[string]string, error) {
+	o := newOptions(opts)
+	row, headers, err := tableRow(table, nil, o)
+	for name, value := range row {
+		row[name] = sanitize(value, false, o)
+	}
+	return row, headers, err
+}
+
+// Cell is a single value of a row with its struct field name and header
+type Cell struct {
+	Field  string
+	Header string
+	Value  string
+}
+
+// TableRowOrdered is TableRow returning the cells of the row in struct
+// declaration order, or sorted by field name for a TableMarshaler,
+// followed by any derived columns
+func TableRowOrdered(table TableStruct, opts ...Option) ([]Cell, error) {
+	o := newOptions(opts)
+	row, headers, err := tableRow(table, nil, o)
+	if err != nil {
+		return []Cell{}, err
+	}
+	names := []string{}
+	if _, ok := table.(TableMarshaler); ok {
+		derived := map[string]bool{}
+		for _, d := range o.derived {
+			derived[d.name] = true
+		}
+		for _, name := range sortedKeys(headers) {
+			if !derived[name] {
+				names = append(names, name)
+			}
+		}
+	} else {
+		for _, f := range visibleFields(reflect.Indirect(reflect.ValueOf(table)).Type(), o) {
+			names = append(names, f.Name)
+		}
+	}
+	for _, d := range o.derived {
+		names = append(names, d.name)
+	}
+
+	cells := make([]Cell, len(names))
+	for i, name := range names {
+		cells[i] = Cell{Field: name, Header: headers[name], Value: sanitize(row[name], false, o)}
+	}
+	return cells, nil
+}
+
+// tableRow returns the values & headers of a row.  Control characters in
+// the values are left for the caller to sanitize.
+func tableRow(table TableStruct, fields []string, o *options) (map[string]string, map[string]string, error) {
 	row := map[string]string{}
-	tbl := reflect.ValueOf(table)
-	fieldCnt := tbl.Type().NumField()
-	headers := make(map[string]string, fieldCnt)
+	if isNilRow(table) {
+		return row, row, fmt.Errorf("Invalid nil row")
+	}
+	tbl := reflect.Indirect(reflect.ValueOf(table))
 
-	for i := 0; i < fieldCnt; i++ {
-		f := reflect.TypeOf(table).Field(i)
-		header, err := table.GetHeader(f.Name)
-		if err != nil {
-			return row, row, err
+	var headers map[string]string
+	var err error
+	if m, ok := table.(TableMarshaler); ok {
+		row, headers, err = marshalRow(m, fields, o)
+	} else {
+		row, headers, err = reflectRow(table, tbl, o)
+	}
+	if err != nil {
+		return row, row, err
+	}
+
+	// derived columns are computed from the whole row
+	for _, d := range o.derived {
+		if _, ok := headers[d.name]; ok {
+			return row, row, fmt.Errorf("Derived column '%s' conflicts with field in %s", d.name, tbl.Type().Name())
+		}
+		headers[d.name] = d.header
+		if o.rawFieldHeaders {
+			headers[d.name] = d.name
 		}
-		fval := tbl.FieldByName(f.Name)
+		row[d.name] = d.fn(table)
+	}
+	return row, headers, nil
+}
+
+// marshalRow returns the row rendered by a TableMarshaler for the fields
+// selected by the caller
+func marshalRow(m TableMarshaler, fields []string, o *options) (map[string]string, map[string]string, error) {
+	for _, field := range fields {
+		if field == "*" || strings.HasPrefix(field, "-") {
+			fields = nil // let the marshaler return every field
+			break
+		}
+	}
+	row, err := m.MarshalTableRow(fields)
+	if err != nil {
+		return map[string]string{}, map[string]string{}, err
+	}
+	headers := make(map[string]string, len(row))
+	for name, value := range row {
+		header := name
+		if !o.rawFieldHeaders {
+			var err error
+			if header, err = m.GetHeader(name); err != nil {
+				return row, row, err
+			}
+			if header == "" {
+				header = name
+			}
+		}
+		headers[name] = header
+		row[name] = value
+	}
+	return row, headers, nil
+}
+
+// reflectRow converts each field of the struct tbl into a value
+func reflectRow(table TableStruct, tbl reflect.Value, o *options) (map[string]string, map[string]string, error) {
+	row := map[string]string{}
+	ti := getTypeInfo(tbl.Type(), o)
+	headers := make(map[string]string, len(ti.fields))
+
+	for i, f := range ti.fields {
+		header, err := f.Name, error(nil)
+		if !o.rawFieldHeaders {
+			if header, err = table.GetHeader(f.Name); err != nil {
+				return row, row, err
+			}
+			if header == "" {
+				header = o.fallbackHeader(f)
+			}
+		}
+		fval := tbl.FieldByIndex(f.Index)
 		headers[f.Name] = header
 		if !fval.IsValid() {
 			continue // this shouldn't happen, but isn't fatal so ignore
 		}
-		switch fval.Kind() {
-		case reflect.String:
-			row[f.Name] = fval.String()
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			row[f.Name] = fmt.Sprintf("%d", fval.Int())
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			row[f.Name] = fmt.Sprintf("%d", fval.Uint())
-		case reflect.Bool:
-			if fval.Bool() {
-				row[f.Name] = "true"
-			} else {
-				row[f.Name] = "false"
-			}
-		default:
-			// unsupported type!  so we mark it unsupported
-			row[f.Name] = NOT_SUPPORTED
+		fi, err := ti.infos[i], ti.errs[i]
+		if err != nil {
+			return row, row, err
 		}
+		if fi.opts.has("omitempty") && isEmptyValue(fval) {
+			row[f.Name] = ""
+			continue
+		}
+		val, err := formatValue(fval, fi, o)
+		if err != nil {
+			return row, row, fmt.Errorf("Unable to format field '%s' in %s: %s", f.Name, tbl.Type().Name(), err.Error())
+		}
+		row[f.Name] = val
 	}
 	return row, headers, nil
 }
 
-// Geneates a table using a list of TableStruct & struct field names in the report
-func GenerateTable(tables []TableStruct, fields []string) error {
-	table := []map[string]string{}
-	headers := map[string]string{}
-	for _, item := range tables {
-		row, h, err := TableRow(item)
-		if err != nil {
-			return err
+var (
+	errorType    = reflect.TypeOf((*error)(nil)).Elem()
+	stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// fieldInfo is the per-field configuration used to format a value
+type fieldInfo struct {
+	opts tagOptions
+	enum map[int64]string
+	base int
+}
+
+func newFieldInfo(t reflect.Type, f reflect.StructField) (*fieldInfo, error) {
+	var err error
+	fi := &fieldInfo{}
+	if _, fi.opts, err = parseFieldTag(t, f); err != nil {
+		return fi, err
+	}
+
+	if fi.opts.has("base") {
+		fi.base, err = strconv.Atoi(fi.opts["base"])
+		if err != nil || fi.base < 2 || fi.base > 36 {
+			return fi, fmt.Errorf("Invalid base '%s' for field '%s' in %s", fi.opts["base"], f.Name, t.Name())
 		}
-		table = append(table, row)
-		headers = h
 	}
 
-	generateTable(table, headers, fields)
-	return nil
+	if fi.opts.has("agg") && !aggregates[fi.opts["agg"]] {
+		return fi, fmt.Errorf("Invalid agg '%s' for field '%s' in %s", fi.opts["agg"], f.Name, t.Name())
+	}
+
+	if fi.opts.has("fill") && utf8.RuneCountInString(fi.opts["fill"]) != 1 {
+		return fi, fmt.Errorf("Invalid fill '%s' for field '%s' in %s", fi.opts["fill"], f.Name, t.Name())
+	}
+
+	if fi.opts.has("maxwidth") {
+		if width, err := strconv.Atoi(fi.opts["maxwidth"]); err != nil || width < 1 {
+			return fi, fmt.Errorf("Invalid maxwidth '%s' for field '%s' in %s", fi.opts["maxwidth"], f.Name, t.Name())
+		}
+	}
+
+	if fi.opts.has("priority") {
+		if _, err := strconv.Atoi(fi.opts["priority"]); err != nil {
+			return fi, fmt.Errorf("Invalid priority '%s' for field '%s' in %s", fi.opts["priority"], f.Name, t.Name())
+		}
+	}
+
+	if fi.opts.has("width") {
+		if width, err := strconv.Atoi(fi.opts["width"]); err != nil || width < 1 {
+			return fi, fmt.Errorf("Invalid width '%s' for field '%s' in %s", fi.opts["width"], f.Name, t.Name())
+		}
+	}
+
+	if fi.opts.has("minwidth") {
+		if width, err := strconv.Atoi(fi.opts["minwidth"]); err != nil || width < 1 {
+			return fi, fmt.Errorf("Invalid minwidth '%s' for field '%s' in %s", fi.opts["minwidth"], f.Name, t.Name())
+		}
+	}
+
+	if fi.opts["wrap"] != "" {
+		if width, err := strconv.Atoi(fi.opts["wrap"]); err != nil || width < 1 {
+			return fi, fmt.Errorf("Invalid wrap '%s' for field '%s' in %s", fi.opts["wrap"], f.Name, t.Name())
+		}
+	}
+
+	if _, ok := truncateNames[fi.opts["trunc"]]; fi.opts.has("trunc") && !ok {
+		return fi, fmt.Errorf("Invalid trunc '%s' for field '%s' in %s", fi.opts["trunc"], f.Name, t.Name())
+	}
+
+	if _, ok := alignNames[fi.opts["align"]]; fi.opts.has("align") && !ok {
+		return fi, fmt.Errorf("Invalid align '%s' for field '%s' in %s", fi.opts["align"], f.Name, t.Name())
+	}
+
+	if _, ok := durationUnits[fi.opts["unit"]]; fi.opts.has("unit") && !ok {
+		return fi, fmt.Errorf("Invalid unit '%s' for field '%s' in %s", fi.opts["unit"], f.Name, t.Name())
+	}
+
+	fi.enum = lookupEnum(t, f.Name)
+	if fi.enum == nil && fi.opts.has("enum") {
+		if fi.enum, err = parseEnum(fi.opts["enum"]); err != nil {
+			return fi, fmt.Errorf("Invalid enum for field '%s' in %s: %s", f.Name, t.Name(), err.Error())
+		}
+	}
+	return fi, nil
 }
 
-// Generates a CSV output instead of a table- no header
-func GenerateCSV(tables []TableStruct, fields []string) error {
-	table := []map[string]string{}
-	for _, item := range tables {
-		row, _, err := TableRow(item)
-		if err != nil {
-			return err
+// isEmptyValue returns true for the zero value of the type, nil and empty
+// slices & maps
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	if v.Type() == timeType && v.CanInterface() {
+		return v.Interface().(time.Time).IsZero()
+	}
+	return v.IsZero()
+}
+
+// isNilValue returns true if v is a nil pointer or interface, or an
+// interface holding a nil pointer
+func isNilValue(v reflect.Value) bool {
+	return !indirectValue(v).IsValid()
+}
+
+// formatValue converts a single field value into a string
+func formatValue(v reflect.Value, fi *fieldInfo, o *options) (string, error) {
+	if fn := lookupTypeFormatter(v.Type()); fn != nil {
+		return fn(v)
+	}
+
+	if v.Type().Implements(errorType) {
+		// check nil, including a nil pointer in an interface, before
+		// calling Error()
+		if isNilValue(v) {
+			if o.nilError != nil {
+				return *o.nilError, nil
+			}
+			return o.null, nil
+		}
+		if v.CanInterface() {
+			return v.Interface().(error).Error(), nil
+		}
+	}
+
+	if v.Type() == timeType {
+		return formatTime(v, fi, o)
+	}
+
+	if v.Type() == durationType {
+		return formatDuration(v, fi), nil
+	}
+
+	if sqlNullTypes[v.Type()] {
+		if !v.FieldByName("Valid").Bool() {
+			return o.null, nil
+		}
+		return formatValue(v.Field(0), fi, o)
+	}
+
+	// types which know how to render themselves, unless they're an enum
+	if v.Type().Implements(stringerType) && fi.enum == nil && v.CanInterface() && !isNilValue(v) {
+		return v.Interface().(fmt.Stringer).String(), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		// resolve to the dynamic/pointed to value
+		if v.IsNil() {
+			return o.null, nil
+		}
+		return formatValue(v.Elem(), fi, o)
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if label, ok := fi.enum[v.Int()]; ok {
+			return label, nil
+		}
+		return formatInt(v.Int(), fi), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if label, ok := fi.enum[int64(v.Uint())]; ok && v.Uint() <= math.MaxInt64 {
+			return label, nil
+		}
+		return formatUint(v.Uint(), fi), nil
+	case reflect.Uintptr:
+		return fmt.Sprintf("0x%x", v.Uint()), nil
+	case reflect.Float32:
+		return formatFloat(v.Float(), 32), nil
+	case reflect.Float64:
+		return formatFloat(v.Float(), 64), nil
+	case reflect.Complex64:
+		return formatComplex(v.Complex(), 64), nil
+	case reflect.Complex128:
+		return formatComplex(v.Complex(), 128), nil
+	case reflect.Bool:
+		if v.Bool() {
+			return "true", nil
+		}
+		return "false", nil
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return formatByteList(v, fi, o)
+		}
+		if !fi.opts.has("json") && isListElem(v.Type().Elem()) {
+			return formatList(v, fi, o)
+		}
+	case reflect.Map:
+		if !fi.opts.has("json") {
+			return formatMap(v, fi, o)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Map, reflect.Array:
+		if (o.jsonFallback || fi.opts.has("json")) && v.CanInterface() {
+			return formatJSON(v)
+		}
+	}
+	if o.bestEffort && v.CanInterface() {
+		return fmt.Sprintf("%v", v.Interface()), nil
+	}
+	// unsupported type!  so we mark it unsupported
+	if o.strictTypes {
+		return "", fmt.Errorf("unsupported kind %s", v.Kind())
+	}
+	return o.notSupported, nil
+}
+
+// formatJSON renders v as single line JSON
+func formatJSON(v reflect.Value) (string, error) {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v.Interface()); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// tableData is a list of TableStruct converted to strings for the
+// selected fields, ready to be rendered in any output format
+type tableData struct {
+	fields  []string
+	headers []string
+	rows    [][]string
+	opts    []tagOptions // header tag options of each column
+	aligns  []Align      // alignment of each column
+	footer  []string     // optional aggregates printed after the rows
+
+	// optional group value of each row & the header of the group field
+	groups      []string
+	groupHeader string
+
+	noHeader bool // skip the header line
+	hasIndex bool // first column is the row number
+}
+
+// tableSource is the rows converted via TableRow which the table of each
+// output format is built from
+type tableSource struct {
+	tables    []TableStruct
+	items     []TableStruct // the converted tables, nil for WithNilRows rows
+	rowType   reflect.Type
+	fieldType reflect.Type // nil if the fields are not struct fields
+	rows      []map[string]string
+	headers   map[string]string
+}
+
+// buildTable converts each TableStruct via TableRow and selects the fields
+// to be rendered in the order they were requested
+func buildTable(tables []TableStruct, fields []string, o *options) (*tableData, error) {
+	s, err := convertTables(tables, fields, o)
+	if err != nil {
+		return &tableData{rows: [][]string{}}, err
+	}
+	return s.table(fields, o)
+}
+
+// convertTables converts each TableStruct via TableRow
+func convertTables(tables []TableStruct, fields []string, o *options) (*tableSource, error) {
+	s := &tableSource{
+		tables:  tables,
+		headers: map[string]string{},
+	}
+	items := make([]TableStruct, 0, len(tables))
+	var rowType reflect.Type
+	firstRow := -1
+	for i, item := range tables {
+		if isNilRow(item) {
+			if o.nilRows {
+				items = append(items, nil)
+			}
+			continue
+		}
+		if rowType == nil {
+			rowType = reflect.Indirect(reflect.ValueOf(item)).Type()
+			firstRow = len(items)
+		} else if o.strictRowTypes && reflect.Indirect(reflect.ValueOf(item)).Type() != rowType {
+			return s, fmt.Errorf("Row %d is a %s, expected %s", i, reflect.Indirect(reflect.ValueOf(item)).Type().Name(), rowType.Name())
+		}
+		items = append(items, item)
+	}
+
+	rows, rowHeaders, err := tableRows(items, fields, o)
+	if err != nil {
+		return s, err
+	}
+	s.rows, s.items = rows, items
+	layout := TableStruct(nil)
+	if firstRow >= 0 {
+		layout = items[firstRow] // the first row defines the layout
+		s.headers = rowHeaders[firstRow]
+	} else if layout = zeroRow(tables); layout != nil {
+		// a typed nil row still defines the layout of a table without rows
+		rowType = reflect.TypeOf(layout).Elem()
+		if _, s.headers, err = tableRow(layout, fields, o); err != nil {
+			return s, err
 		}
-		table = append(table, row)
 	}
+	s.rowType, s.fieldType = rowType, rowType
+
+	// the fields of a TableMarshaler are whatever it returns
+	if _, ok := layout.(TableMarshaler); ok {
+		s.fieldType = nil
+	}
+	return s, nil
+}
 
-	generateCSV(table, fields)
+// zeroRow returns a pointer to the zero value of the struct type of the
+// first typed nil row or nil if there is none
+func zeroRow(tables []TableStruct) TableStruct {
+	for _, table := range tables {
+		if table == nil {
+			continue
+		}
+		if t := reflect.TypeOf(table); t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct {
+			if row, ok := reflect.New(t.Elem()).Interface().(TableStruct); ok {
+				return row
+			}
+		}
+	}
 	return nil
 }
 
-func generateTable(data []map[string]string, fieldMap map[string]string, fields []string) {
-	table := [][]string{}
-	colWidth := make([]int, len(fields))
+// table selects the fields to be rendered in the order they were requested
+func (s *tableSource) table(fields []string, o *options) (*tableData, error) {
+	t := &tableData{
+		rows: [][]string{},
+	}
+	rowType, headers := s.rowType, s.headers
+	fields, err := resolveFields(fields, s.fieldType, headers, o)
+	if err != nil {
+		return t, err
+	}
+	if err = checkHeaderOverrides(rowType, headers, o); err != nil {
+		return t, err
+	}
 
-	// figure out width of column headers
+	t.fields = fields
+	t.headers = make([]string, len(fields))
+	t.opts = make([]tagOptions, len(fields))
+	t.aligns = make([]Align, len(fields))
 	for i, field := range fields {
-		colWidth[i] = len(fieldMap[field])
+		t.opts[i] = fieldTagOptions(rowType, field)
+		t.aligns[i] = columnAlign(rowType, field, t.opts[i], o)
+		t.headers[i] = sanitize(headers[field], false, o)
+		if !o.csv && !o.rawFieldHeaders {
+			t.headers[i] = o.headerCase.apply(t.headers[i])
+		}
+		if header, ok := o.headerOverrides[field]; ok {
+			t.headers[i] = header
+		}
 	}
-
-	// calc max len of every column & build our row
-	for _, r := range data {
-		row := make([]string, len(fields))
+	for _, row := range s.rows {
+		values := make([]string, len(fields))
 		for i, field := range fields {
-			row[i] = r[field]
-			if len(r[field]) > colWidth[i] {
-				colWidth[i] = len(r[field])
+			if row == nil {
+				values[i] = o.null
+			} else {
+				values[i] = sanitize(row[field], o.wrap || o.multiLine || t.opts[i].has("wrap"), o)
 			}
 		}
-		table = append(table, row)
+		t.rows = append(t.rows, values)
 	}
 
-	// build our fstring for each row
-	fstrings := make([]string, len(fields))
-	for i, width := range colWidth {
-		fstrings[i] = fmt.Sprintf("%%-%ds", width)
+	if t.footer, err = buildFooter(s.tables, rowType, fields, t.opts); err != nil {
+		return t, err
 	}
-	fstring := strings.Join(fstrings, " | ")
-	fstring = fmt.Sprintf("%s\n", fstring)
 
-	// fmt.Sprintf() expects []interface...
-	finter := make([]interface{}, len(fields))
-	for i, field := range fields {
-		finter[i] = fieldMap[field]
+	if o.uniqueHeaders {
+		if err = checkDuplicateHeaders(t.fields, t.headers, o); err != nil {
+			return t, err
+		}
 	}
 
-	// print the header
-	headerLine := fmt.Sprintf(fstring, finter...)
-	fmt.Printf("%s%s\n", headerLine, strings.Repeat("=", len(headerLine)-1))
+	if o.indexColumn {
+		t.addIndex(o.indexHeader)
+	}
+	return t, nil
+}
+
+// isNilRow returns true for nil and nil pointer rows
+func isNilRow(table TableStruct) bool {
+	if table == nil {
+		return true
+	}
+	v := reflect.ValueOf(table)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+// addIndex prepends a column with the 1-based row number
+func (t *tableData) addIndex(header string) {
+	t.fields = append([]string{""}, t.fields...)
+	t.headers = append([]string{header}, t.headers...)
+	t.opts = append([]tagOptions{{}}, t.opts...)
+	t.aligns = append([]Align{AlignRight}, t.aligns...)
+	if t.footer != nil {
+		t.footer = append([]string{""}, t.footer...)
+	}
+	for i, row := range t.rows {
+		t.rows[i] = append([]string{""}, row...)
+	}
+	t.hasIndex = true
+	t.renumber()
+}
+
+// renumber updates the index column after the rows have been reordered
+func (t *tableData) renumber() {
+	if !t.hasIndex {
+		return
+	}
+	for i, row := range t.rows {
+		row[0] = strconv.Itoa(i + 1)
+	}
+}
+
+// Geneates a table using a list of TableStruct & struct field names in the report
+// A nil or empty list of fields renders every exported, non-excluded field
+// of the first row's type.  Rows of other types leave missing fields blank.
+func GenerateTable(tables []TableStruct, fields []string, opts ...Option) error {
+	o := newOptions(opts)
+	o.table = true
+	t, err := buildTable(tables, fields, o)
+	if err != nil {
+		return err
+	}
+
+	return generateTable(os.Stdout, t, o)
+}
+
+// GenerateTableOne is GenerateTable for a single row
+func GenerateTableOne(table TableStruct, fields []string, opts ...Option) error {
+	return GenerateTable([]TableStruct{table}, fields, opts...)
+}
+
+// GenerateTableString is GenerateTable returning the table as a string
+// instead of printing it
+func GenerateTableString(tables []TableStruct, fields []string, opts ...Option) (string, error) {
+	o := newOptions(opts)
+	o.table = true
+	t, err := buildTable(tables, fields, o)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	err = generateTable(&buf, t, o)
+	return buf.String(), err
+}
+
+// Generates a CSV output instead of a table- no header unless WithCSVHeader
+// is set
+func GenerateCSV(tables []TableStruct, fields []string, opts ...Option) error {
+	o := newOptions(opts)
+	o.csv = true
+	t, err := buildTable(tables, fields, o)
+	if err != nil {
+		return err
+	}
+
+	return generateCSV(os.Stdout, t, o)
+}
+
+// GenerateCSVOne is GenerateCSV for a single row
+func GenerateCSVOne(table TableStruct, fields []string, opts ...Option) error {
+	return GenerateCSV([]TableStruct{table}, fields, opts...)
+}
+
+// generateTable prints the table & returns the first error writing to w
+func generateTable(w io.Writer, t *tableData, o *options) error {
+	padded := alignDecimals(escapeTable(t, o))
+	fitTerminal(w, o)
+	if o.verticalFallback && !fits(padded, o) {
+		// records are not padded like the columns of a table
+		return generateVerticalFallback(w, t, o)
+	}
+	t = padded
+	hidden := dropColumns(t, o)
+	colWidth := columnWidths(t, o)
+	if err := printHeader(w, t, colWidth, o); err != nil {
+		return err
+	}
 
 	// print each row
-	for _, row := range data {
-		values := make([]interface{}, len(fields))
-		for i, field := range fields {
-			values[i] = row[field]
+	for i, row := range t.rows {
+		lines := []string{}
+		if t.groups != nil && (i == 0 || t.groups[i] != t.groups[i-1]) {
+			lines = append(lines, groupLine(t, i))
+		} else if o.stripe > 0 && i > 0 && i%o.stripe == 0 {
+			lines = append(lines, separatorLine(colWidth, o))
 		}
-		fmt.Printf(fstring, values...)
+		lines = append(lines, formatRow(t, row, colWidth, false, o))
+		if err := printLines(w, lines); err != nil {
+			return err
+		}
+	}
+
+	lines := []string{}
+	// print the aggregates
+	if t.footer != nil {
+		lines = append(lines, separatorLine(colWidth, o), formatRow(t, t.footer, colWidth, false, o))
+	}
+	if o.rowCount {
+		lines = append(lines, rowCountLine(len(t.rows)))
 	}
+	if hidden > 0 {
+		lines = append(lines, hiddenLine(hidden))
+	}
+	return printLines(w, lines)
 }
 
-func generateCSV(data []map[string]string, fields []string) error {
-	var err error
-	fStr := make([]string, len(fields))
-	for i, _ := range fields {
-		fStr[i] = "%s"
+// printLines prints each line & returns the first error
+func printLines(w io.Writer, lines []string) error {
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	w := csv.NewWriter(os.Stdout)
-	defer w.Flush()
+// formatRow returns the lines for a row of values truncated or wrapped &
+// padded to the width of each column.  Values are padded with the fill
+// character of their column, if any, and headers are aligned per
+// WithHeaderAlignment.  Rows with wrapped values span multiple lines with
+// the other columns left blank.
+func formatRow(t *tableData, values []string, colWidth []int, header bool, o *options) string {
+	cells := make([][]string, len(values))
+	height := 1
+	for i, value := range values {
+		cells[i] = cellLines(t, i, value, colWidth[i], o)
+		if len(cells[i]) > height {
+			height = len(cells[i])
+		}
+	}
 
-	for _, row := range data {
-		values := make([]string, len(fields))
-		for i, field := range fields {
-			values[i] = row[field]
+	lines := make([]string, height)
+	for l := range lines {
+		line := make([]string, len(cells))
+		for i := range cells {
+			align, fill := alignAt(t.aligns, i), " "
+			if header {
+				if o.headerAlign != AlignAuto {
+					align = o.headerAlign
+				}
+			} else if i < len(t.opts) && t.opts[i].has("fill") {
+				fill = t.opts[i]["fill"]
+			}
+			value := ""
+			if l < len(cells[i]) {
+				value = cells[i][l]
+			} else {
+				fill = " "
+			}
+			line[i] = pad(value, colWidth[i], align, fill)
+		}
+		lines[l] = strings.Join(line, o.columnSeparator())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// printHeader prints the title, column groups, header & rule of a table
+func printHeader(w io.Writer, t *tableData, colWidth []int, o *options) error {
+	if err := printTitle(w, colWidth, o); err != nil || t.noHeader {
+		return err
+	}
+	lines := []string{}
+	if line, ok := columnGroupLine(t, colWidth, o); ok {
+		lines = append(lines, line)
+	}
+	lines = append(lines, formatRow(t, t.headers, colWidth, true, o))
+	if o.headerRule != "" {
+		lines = append(lines, repeatWidth(o.headerRule, tableWidth(colWidth, o)))
+	}
+	return printLines(w, lines)
+}
+
+// rowCountLine is the number of rows printed after the table
+func rowCountLine(rows int) string {
+	if rows == 1 {
+		return "(1 row)"
+	}
+	return fmt.Sprintf("(%d rows)", rows)
+}
+
+// separatorLine returns a line of dashes matching each column with a +
+// under each column separator.  Box drawing separators such as " │ " get
+// a ─ line with ┼ junctions.
+func separatorLine(colWidth []int, o *options) string {
+	dash, cross := '-', '+'
+	if strings.ContainsRune(o.columnSeparator(), '│') {
+		dash, cross = '─', '┼'
+	}
+	cols := make([]string, len(colWidth))
+	for i, width := range colWidth {
+		cols[i] = strings.Repeat(string(dash), width)
+	}
+	junction := strings.Map(func(r rune) rune {
+		if r == ' ' {
+			return dash
 		}
-		if err = w.Write(values); err != nil {
+		return cross
+	}, o.columnSeparator())
+	return strings.Join(cols, junction)
+}
+
+func generateCSV(out io.Writer, t *tableData, o *options) error {
+	hash := sha256.New()
+	w := csv.NewWriter(io.MultiWriter(out, hash))
+
+	if o.csvHeader {
+		if err := w.Write(t.headers); err != nil {
+			return err
+		}
+	}
+	for _, row := range t.rows {
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	if o.csvFooter && t.footer != nil {
+		if err := w.Write(t.footer); err != nil {
 			return err
 		}
 	}
-	return err
+	if o.csvTrailer {
+		// the checksum covers every record before the trailer
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return err
+		}
+		trailer := []string{fmt.Sprintf("#rows=%d", len(t.rows))}
+		if o.csvChecksum {
+			trailer = append(trailer, "sha256="+hex.EncodeToString(hash.Sum(nil)))
+		}
+		if err := w.Write(trailer); err != nil {
+			return err
+		}
+	}
+
+	// Write() buffers so errors writing to out are only known after Flush()
+	w.Flush()
+	return w.Error()
 }
 
+// GetHeaderTag returns the header name in the tag of the given field.
+// v may be a struct or a pointer/interface wrapping a struct.
 func GetHeaderTag(v reflect.Value, fieldName string) (string, error) {
-	field, ok := v.Type().FieldByName(fieldName)
+	t, err := structType(v)
+	if err != nil {
+		return "", err
+	}
+	return GetHeaderTagType(t, fieldName)
+}
+
+// GetHeaderTagType is GetHeaderTag for callers which only have the type
+func GetHeaderTagType(t reflect.Type, fieldName string) (string, error) {
+	field, t, err := structField(t, fieldName)
+	if err != nil {
+		return "", err
+	}
+	if isExcluded(field) {
+		return "", fmt.Errorf("Excluded field '%s' in %s", fieldName, t.Name())
+	}
+	tag, _, err := parseFieldTag(t, field)
+	return tag, err
+}
+
+// GetHeaderOptions returns the options which follow the header name in the
+// tag of the given field
+func GetHeaderOptions(v reflect.Value, fieldName string) (map[string]string, error) {
+	t, err := structType(v)
+	if err != nil {
+		return map[string]string{}, err
+	}
+	field, t, err := structField(t, fieldName)
+	if err != nil {
+		return map[string]string{}, err
+	}
+	_, opts, err := parseFieldTag(t, field)
+	return opts, err
+}
+
+// structType returns the struct type of v after resolving any interfaces
+// & pointers
+func structType(v reflect.Value) (reflect.Type, error) {
+	for v.IsValid() && v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil, fmt.Errorf("Invalid value")
+	}
+	return v.Type(), nil
+}
+
+// structField looks up the field in the struct type t after resolving any
+// pointers and returns the field & struct type
+func structField(t reflect.Type, fieldName string) (reflect.StructField, reflect.Type, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return reflect.StructField{}, t, fmt.Errorf("Invalid type %s is not a struct", t.String())
+	}
+	field, ok := t.FieldByName(fieldName)
 	if !ok {
-		return "", fmt.Errorf("Invalid field '%s' in %s", fieldName, v.Type().Name())
+		return field, t, fmt.Errorf("Invalid field '%s' in %s", fieldName, t.Name())
 	}
-	tag := string(field.Tag.Get(TABLE_HEADER_TAG))
-	return tag, nil
+	return field, t, nil
 }
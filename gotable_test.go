@@ -0,0 +1,886 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"reflect"
+	"testing"
+)
+
+type color int
+
+func (c color) String() string {
+	return [...]string{"red", "green", "blue"}[c]
+}
+
+type point struct{ X, Y int }
+
+func (p *point) String() string {
+	return fmt.Sprintf("(%d,%d)", p.X, p.Y)
+}
+
+type stringerRow struct {
+	Color color        `header:"Color"`
+	Any   interface{}  `header:"Any"`
+	Point *point       `header:"Point"`
+	Str   fmt.Stringer `header:"Stringer"`
+}
+
+func (r stringerRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestStringer(t *testing.T) {
+	tests := []struct {
+		name string
+		row  stringerRow
+		want map[string]string
+	}{
+		{
+			name: "values",
+			row:  stringerRow{Color: 1, Any: color(2), Point: &point{1, 2}, Str: color(0)},
+			want: map[string]string{"Color": "green", "Any": "blue", "Point": "(1,2)", "Str": "red"},
+		},
+		{
+			name: "nil",
+			row:  stringerRow{},
+			want: map[string]string{"Color": "red", "Any": "NULL", "Point": "NULL", "Str": "NULL"},
+		},
+		{
+			name: "typed nil",
+			row:  stringerRow{Any: (*point)(nil), Str: (*point)(nil)},
+			want: map[string]string{"Color": "red", "Any": "NULL", "Point": "NULL", "Str": "NULL"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := TableRow(tt.row, WithNullPlaceholder("NULL"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TableRow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type enumStringerRow struct {
+	Color color `header:"Color,enum=0:r;1:g;2:b"`
+}
+
+func (r enumStringerRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestStringerEnum(t *testing.T) {
+	got, _, err := TableRow(enumStringerRow{Color: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["Color"] != "b" {
+		t.Errorf("TableRow() = %q, want the enum label %q", got["Color"], "b")
+	}
+}
+
+type complexRow struct {
+	C64  complex64  `header:"C64"`
+	C128 complex128 `header:"C128"`
+}
+
+func (r complexRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestComplex(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		name string
+		c    complex128
+		want string
+	}{
+		{"zero", 0, "(0+0i)"},
+		{"real", 1.5, "(1.5+0i)"},
+		{"imaginary", 2i, "(0+2i)"},
+		{"both", complex(1.5, -2), "(1.5-2i)"},
+		{"nan real", complex(nan, 1), "(NaN+1i)"},
+		{"nan imaginary", complex(1, nan), "(1+NaNi)"},
+		{"inf", complex(math.Inf(1), math.Inf(-1)), "(+Inf-Infi)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := complexRow{C64: complex64(tt.c), C128: tt.c}
+			values, _, err := TableRow(row)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if values["C64"] != tt.want || values["C128"] != tt.want {
+				t.Errorf("TableRow() = %q, %q, want %q", values["C64"], values["C128"], tt.want)
+			}
+
+			var buf bytes.Buffer
+			err = RenderMulti([]TableStruct{row}, []string{"C128"}, []Output{{Format: FormatCSV, Writer: &buf}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want+"\n" {
+				t.Errorf("CSV = %q, want %q", buf.String(), tt.want+"\n")
+			}
+		})
+	}
+}
+
+type unsupportedRow struct {
+	Name string   `header:"Name"`
+	Fn   func()   `header:"Fn"`
+	Chan chan int `header:"Chan"`
+}
+
+func (r unsupportedRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestStrictTypes(t *testing.T) {
+	rows := []TableStruct{unsupportedRow{Name: "a"}}
+	tests := []struct {
+		name  string
+		opts  []Option
+		table string
+		csv   string
+		err   string
+	}{
+		{
+			name:  "lenient",
+			table: "Name | Fn         | Chan      \n==============================\na    | NO_SUPPORT | NO_SUPPORT\n",
+			csv:   "a,NO_SUPPORT,NO_SUPPORT\n",
+		},
+		{
+			name: "strict",
+			opts: []Option{WithStrictTypes()},
+			err:  "Unable to format field 'Fn' in unsupportedRow: unsupported kind func",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := GenerateTableString(rows, nil, tt.opts...)
+			if (err == nil && tt.err != "") || (err != nil && err.Error() != tt.err) {
+				t.Errorf("GenerateTableString() error = %v, want %q", err, tt.err)
+			}
+			if table != tt.table {
+				t.Errorf("GenerateTableString() = %q, want %q", table, tt.table)
+			}
+
+			var buf bytes.Buffer
+			err = RenderMulti(rows, nil, []Output{{Format: FormatCSV, Writer: &buf}}, tt.opts...)
+			if (err == nil && tt.err != "") || (err != nil && err.Error() != tt.err) {
+				t.Errorf("RenderMulti() error = %v, want %q", err, tt.err)
+			}
+			if buf.String() != tt.csv {
+				t.Errorf("RenderMulti() = %q, want %q", buf.String(), tt.csv)
+			}
+		})
+	}
+}
+
+func TestNotSupportedPlaceholder(t *testing.T) {
+	rows := []TableStruct{unsupportedRow{Name: "a"}}
+	tests := []struct {
+		placeholder string
+		table       string
+		csv         string
+	}{
+		{"", "Name | Fn | Chan\n================\na    |    |     \n", "a,,\n"},
+		{"-", "Name | Fn | Chan\n================\na    | -  | -   \n", "a,-,-\n"},
+		{"unsupported", "Name | Fn          | Chan       \n================================\na    | unsupported | unsupported\n", "a,unsupported,unsupported\n"},
+	}
+	for _, tt := range tests {
+		table, err := GenerateTableString(rows, nil, WithNotSupported(tt.placeholder))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if table != tt.table {
+			t.Errorf("GenerateTableString(%q) = %q, want %q", tt.placeholder, table, tt.table)
+		}
+
+		var buf bytes.Buffer
+		err = RenderMulti(rows, nil, []Output{{Format: FormatCSV, Writer: &buf}}, WithNotSupported(tt.placeholder))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tt.csv {
+			t.Errorf("RenderMulti(%q) = %q, want %q", tt.placeholder, buf.String(), tt.csv)
+		}
+	}
+}
+
+func TestNilRows(t *testing.T) {
+	rows := []TableStruct{colorRow{Name: "a", Value: "1"}, nil, (*colorRow)(nil), &colorRow{Name: "b", Value: "2"}}
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{"skip", nil, "Name | Value\n============\na    | 1    \nb    | 2    \n"},
+		{"blank", []Option{WithNilRows()}, "Name | Value\n============\na    | 1    \n     |      \n     |      \nb    | 2    \n"},
+		{"placeholder", []Option{WithNilRows(), WithNullPlaceholder("-")}, "Name | Value\n============\na    | 1    \n-    | -    \n-    | -    \nb    | 2    \n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateTableString(rows, nil, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("GenerateTableString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// pairRow renders its own columns A & B instead of its struct field
+type pairRow struct {
+	X string `header:"X"`
+}
+
+func (r pairRow) GetHeader(fieldName string) (string, error) {
+	return "Col " + fieldName, nil
+}
+
+func (r pairRow) MarshalTableRow(fields []string) (map[string]string, error) {
+	return map[string]string{"B": "2", "A": "1"}, nil
+}
+
+func TestTableRowOrdered(t *testing.T) {
+	derived := func(TableStruct) string { return "derived" }
+	tests := []struct {
+		name string
+		row  TableStruct
+		opts []Option
+		want []Cell
+	}{
+		{
+			name: "struct",
+			row:  colorRow{Name: "a", Value: "1"},
+			want: []Cell{{"Name", "Name", "a"}, {"Value", "Value", "1"}},
+		},
+		{
+			name: "pointer",
+			row:  &colorRow{Name: "a", Value: "1"},
+			want: []Cell{{"Name", "Name", "a"}, {"Value", "Value", "1"}},
+		},
+		{
+			name: "skips unexported & excluded fields",
+			row:  layoutRow{Host: "h", secret: "s", Port: 22, Token: "t"},
+			want: []Cell{{"Host", "Host", "h"}, {"Port", "Port", "22"}},
+		},
+		{
+			name: "derived",
+			row:  colorRow{Name: "a", Value: "1"},
+			opts: []Option{WithDerivedColumn("D", "Derived", derived)},
+			want: []Cell{{"Name", "Name", "a"}, {"Value", "Value", "1"}, {"D", "Derived", "derived"}},
+		},
+		{
+			name: "marshaler",
+			row:  pairRow{X: "x"},
+			want: []Cell{{"A", "Col A", "1"}, {"B", "Col B", "2"}},
+		},
+		{
+			name: "marshaler derived",
+			row:  pairRow{X: "x"},
+			opts: []Option{WithDerivedColumn("D", "Derived", derived)},
+			want: []Cell{{"A", "Col A", "1"}, {"B", "Col B", "2"}, {"D", "Derived", "derived"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TableRowOrdered(tt.row, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TableRowOrdered() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+type ratioRow struct {
+	Hits   int `header:"Hits"`
+	Misses int `header:"Misses"`
+}
+
+func (r ratioRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestDerivedColumn(t *testing.T) {
+	ratio := WithDerivedColumn("Ratio", "Hit %", func(row TableStruct) string {
+		r := row.(ratioRow)
+		return fmt.Sprintf("%d%%", 100*r.Hits/(r.Hits+r.Misses))
+	})
+	rows := []TableStruct{ratioRow{Hits: 3, Misses: 1}, ratioRow{Hits: 1, Misses: 1}}
+	tests := []struct {
+		name   string
+		format Format
+		fields []string
+		want   string
+	}{
+		{"all fields", FormatTable, nil, "Hits | Misses | Hit %\n=====================\n   3 |      1 | 75%  \n   1 |      1 | 50%  \n"},
+		{"selected first", FormatTable, []string{"Ratio", "Hits"}, "Hit % | Hits\n============\n75%   |    3\n50%   |    1\n"},
+		{"by header", FormatTable, []string{"hit %"}, "Hit %\n=====\n75%  \n50%  \n"},
+		{"csv", FormatCSV, []string{"Hits", "Ratio"}, "3,75%\n1,50%\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := RenderMulti(rows, tt.fields, []Output{{Format: tt.format, Writer: &buf}}, ratio); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("RenderMulti() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+
+	got, _, err := TableRow(rows[0], ratio)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["Ratio"] != "75%" {
+		t.Errorf("TableRow() Ratio = %q, want %q", got["Ratio"], "75%")
+	}
+}
+
+func TestStripe(t *testing.T) {
+	rows := []TableStruct{colorRow{Name: "a", Value: "1"}, colorRow{Name: "b", Value: "2"}, colorRow{Name: "c", Value: "3"}}
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, "Name | Value\n============\na    | 1    \nb    | 2    \nc    | 3    \n"},
+		{1, "Name | Value\n============\na    | 1    \n-----+------\nb    | 2    \n-----+------\nc    | 3    \n"},
+		{2, "Name | Value\n============\na    | 1    \nb    | 2    \n-----+------\nc    | 3    \n"},
+		{5, "Name | Value\n============\na    | 1    \nb    | 2    \nc    | 3    \n"},
+	}
+	for _, tt := range tests {
+		got, err := GenerateTableString(rows, nil, WithStripe(tt.n))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("WithStripe(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+type span struct {
+	Lo   int    `json:"lo"`
+	Hi   int    `json:"hi"`
+	Note string `json:"note,omitempty"`
+}
+
+type jsonRow struct {
+	Range  span   `header:"Range"`
+	Tagged span   `header:"Tagged,json"`
+	List   []span `header:"List"`
+}
+
+func (r jsonRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestJSONFallback(t *testing.T) {
+	row := jsonRow{Range: span{Lo: 1, Hi: 2}, Tagged: span{Lo: 3, Hi: 4, Note: "a<b"}, List: []span{{Lo: 5, Hi: 6}}}
+	tests := []struct {
+		name string
+		opts []Option
+		want map[string]string
+	}{
+		{
+			name: "tag only",
+			want: map[string]string{"Range": "NO_SUPPORT", "Tagged": `{"lo":3,"hi":4,"note":"a<b"}`, "List": "NO_SUPPORT"},
+		},
+		{
+			name: "fallback",
+			opts: []Option{WithJSONFallback()},
+			want: map[string]string{"Range": `{"lo":1,"hi":2}`, "Tagged": `{"lo":3,"hi":4,"note":"a<b"}`, "List": `[{"lo":5,"hi":6}]`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := TableRow(row, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TableRow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type resultRow struct {
+	Host string `header:"Host"`
+	Err  error  `header:"Error"`
+}
+
+func (r resultRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestErrorField(t *testing.T) {
+	tests := []struct {
+		name string
+		row  resultRow
+		opts []Option
+		want string
+	}{
+		{"error", resultRow{Host: "a", Err: errors.New("timeout")}, nil, "timeout"},
+		{"nil", resultRow{Host: "a"}, nil, ""},
+		{"nil placeholder", resultRow{Host: "a"}, []Option{WithNullPlaceholder("-")}, "-"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := TableRow(tt.row, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got["Err"] != tt.want {
+				t.Errorf("TableRow() Err = %q, want %q", got["Err"], tt.want)
+			}
+		})
+	}
+}
+
+type hostError struct{ host string }
+
+func (e *hostError) Error() string {
+	return "unreachable " + e.host
+}
+
+func TestNilError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		opts []Option
+		want string
+	}{
+		{"nil", nil, []Option{WithNilError("ok")}, "ok"},
+		{"nil falls back to null", nil, []Option{WithNullPlaceholder("-")}, "-"},
+		{"nil error wins", nil, []Option{WithNullPlaceholder("-"), WithNilError("ok")}, "ok"},
+		{"typed nil", (*hostError)(nil), []Option{WithNilError("ok")}, "ok"},
+		{"custom", &hostError{"a"}, []Option{WithNilError("ok")}, "unreachable a"},
+		{"wrapped", fmt.Errorf("ping: %w", &hostError{"a"}), nil, "ping: unreachable a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := TableRow(resultRow{Host: "a", Err: tt.err}, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got["Err"] != tt.want {
+				t.Errorf("TableRow() Err = %q, want %q", got["Err"], tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexColumn(t *testing.T) {
+	rows := []TableStruct{colorRow{Name: "a", Value: "1"}, nil, colorRow{Name: "b", Value: "2"}}
+	tests := []struct {
+		name   string
+		format Format
+		opts   []Option
+		want   string
+	}{
+		{"table", FormatTable, nil, "# | Value\n=========\n1 | 1    \n2 | 2    \n"},
+		{"table nil rows", FormatTable, []Option{WithNilRows()}, "# | Value\n=========\n1 | 1    \n2 |      \n3 | 2    \n"},
+		{"csv", FormatCSV, nil, "1,1\n2,2\n"},
+		{"csv header", FormatCSV, []Option{WithNilRows(), WithCSVHeader()}, "#,Value\n1,1\n2,\n3,2\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			opts := append([]Option{WithIndexColumn("#")}, tt.opts...)
+			if err := RenderMulti(rows, []string{"Value"}, []Output{{Format: tt.format, Writer: &buf}}, opts...); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("RenderMulti() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestStrictRowTypes(t *testing.T) {
+	tests := []struct {
+		name string
+		rows []TableStruct
+		opts []Option
+		want string
+		err  string
+	}{
+		{
+			name: "same type",
+			rows: []TableStruct{colorRow{Name: "a", Value: "1"}, &colorRow{Name: "b", Value: "2"}},
+			opts: []Option{WithStrictRowTypes()},
+			want: "Name | Value\n============\na    | 1    \nb    | 2    \n",
+		},
+		{
+			name: "nil rows are skipped",
+			rows: []TableStruct{nil, colorRow{Name: "a", Value: "1"}, (*ratioRow)(nil)},
+			opts: []Option{WithStrictRowTypes()},
+			want: "Name | Value\n============\na    | 1    \n",
+		},
+		{
+			name: "mixed types",
+			rows: []TableStruct{colorRow{Name: "a", Value: "1"}, wideRow{Name: "b", Value: "2", Extra: "3"}},
+			opts: []Option{WithStrictRowTypes()},
+			err:  "Row 1 is a wideRow, expected colorRow",
+		},
+		{
+			name: "mixed types allowed",
+			rows: []TableStruct{colorRow{Name: "a", Value: "1"}, wideRow{Name: "b", Value: "2", Extra: "3"}},
+			want: "Name | Value\n============\na    | 1    \nb    | 2    \n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateTableString(tt.rows, nil, tt.opts...)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("GenerateTableString() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("GenerateTableString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type pointerRow struct {
+	Addr uintptr    `header:"Addr"`
+	C    complex128 `header:"C"`
+}
+
+func (r pointerRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestUintptrAndComplexFormatters(t *testing.T) {
+	row := pointerRow{Addr: 0xc000012345, C: complex(1, 2)}
+	tests := []struct {
+		name     string
+		register bool
+		want     map[string]string
+	}{
+		{"builtin", false, map[string]string{"Addr": "0xc000012345", "C": "(1+2i)"}},
+		{"registered", true, map[string]string{"Addr": "824633795397", "C": "1+2i"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withFormatters(t)
+			if tt.register {
+				RegisterTypeFormatter(reflect.TypeOf(uintptr(0)), func(v reflect.Value) (string, error) {
+					return fmt.Sprintf("%d", v.Uint()), nil
+				})
+				RegisterTypeFormatter(reflect.TypeOf(complex128(0)), func(v reflect.Value) (string, error) {
+					c := v.Complex()
+					return fmt.Sprintf("%g%+gi", real(c), imag(c)), nil
+				})
+			}
+			got, _, err := TableRow(row)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TableRow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if got, _, _ := TableRow(pointerRow{}); got["Addr"] != "0x0" {
+		t.Errorf("TableRow() Addr = %q, want %q", got["Addr"], "0x0")
+	}
+}
+
+func TestBestEffort(t *testing.T) {
+	row := jsonRow{Range: span{Lo: 1, Hi: 2}, Tagged: span{Lo: 3, Hi: 4}, List: []span{{Lo: 5, Hi: 6, Note: "x"}}}
+	tests := []struct {
+		name string
+		opts []Option
+		want map[string]string
+	}{
+		{
+			name: "disabled",
+			opts: []Option{WithBestEffort(false)},
+			want: map[string]string{"Range": "NO_SUPPORT", "Tagged": `{"lo":3,"hi":4}`, "List": "NO_SUPPORT"},
+		},
+		{
+			name: "enabled",
+			opts: []Option{WithBestEffort(true)},
+			want: map[string]string{"Range": "{1 2 }", "Tagged": `{"lo":3,"hi":4}`, "List": "[{5 6 x}]"},
+		},
+		{
+			name: "wins over strict types",
+			opts: []Option{WithStrictTypes(), WithBestEffort(true)},
+			want: map[string]string{"Range": "{1 2 }", "Tagged": `{"lo":3,"hi":4}`, "List": "[{5 6 x}]"},
+		},
+		{
+			name: "JSON fallback first",
+			opts: []Option{WithJSONFallback(), WithBestEffort(true)},
+			want: map[string]string{"Range": `{"lo":1,"hi":2}`, "Tagged": `{"lo":3,"hi":4}`, "List": `[{"lo":5,"hi":6,"note":"x"}]`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := TableRow(row, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TableRow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	got, _, err := TableRow(unsupportedRow{Name: "a"}, WithBestEffort(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["Chan"] != "<nil>" {
+		t.Errorf("TableRow() Chan = %q, want %q", got["Chan"], "<nil>")
+	}
+}
+
+func TestRowCount(t *testing.T) {
+	row := colorRow{Name: "a", Value: "1"}
+	tests := []struct {
+		name   string
+		rows   []TableStruct
+		format Format
+		opts   []Option
+		want   string
+	}{
+		{"none", []TableStruct{(*colorRow)(nil)}, FormatTable, nil, "Name | Value\n============\n(0 rows)\n"},
+		{"one", []TableStruct{row}, FormatTable, nil, "Name | Value\n============\na    | 1    \n(1 row)\n"},
+		{"nil rows skipped", []TableStruct{row, nil, row}, FormatTable, nil, "Name | Value\n============\na    | 1    \na    | 1    \n(2 rows)\n"},
+		{"after aggregates", []TableStruct{priceRow{Name: "a", Price: 1.5}, priceRow{Name: "b", Price: 2}}, FormatTable, nil,
+			"Name | Price\n============\na    |   1.5\nb    |   2  \n-----+------\n     |   3.5\n(2 rows)\n"},
+		{"not in csv", []TableStruct{row}, FormatCSV, nil, "a,1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			opts := append([]Option{WithRowCount()}, tt.opts...)
+			if err := RenderMulti(tt.rows, nil, []Output{{Format: tt.format, Writer: &buf}}, opts...); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("RenderMulti() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+
+	got, err := GenerateTableString([]TableStruct{row}, nil, WithRowCount())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Name | Value\n============\na    | 1    \n(1 row)\n"; got != want {
+		t.Errorf("GenerateTableString() = %q, want %q", got, want)
+	}
+}
+
+// captureStdout returns what fn prints to os.Stdout
+func captureStdout(t *testing.T, fn func() error) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	out := make(chan string)
+	go func() {
+		b, _ := ioutil.ReadAll(r)
+		out <- string(b)
+	}()
+	err = fn()
+	w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return <-out
+}
+
+func TestGenerateOne(t *testing.T) {
+	row := &colorRow{Name: "a", Value: "1"}
+	tests := []struct {
+		name string
+		fn   func() error
+		want string
+	}{
+		{
+			name: "table",
+			fn:   func() error { return GenerateTableOne(row, nil) },
+			want: "Name | Value\n============\na    | 1    \n",
+		},
+		{
+			name: "table fields",
+			fn:   func() error { return GenerateTableOne(row, []string{"Value"}, WithRowCount()) },
+			want: "Value\n=====\n1    \n(1 row)\n",
+		},
+		{
+			name: "csv",
+			fn:   func() error { return GenerateCSVOne(row, nil, WithCSVHeader()) },
+			want: "Name,Value\na,1\n",
+		},
+		{
+			name: "vertical",
+			fn:   func() error { return GenerateVerticalOne(os.Stdout, row, []string{"Value", "Name"}) },
+			want: "*** row 1 ***\nValue: 1\n Name: a\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := captureStdout(t, tt.fn); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColumnSeparator(t *testing.T) {
+	rows := []TableStruct{colorRow{Name: "a", Value: "1"}, colorRow{Name: "bb", Value: "2"}}
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{"spaces", []Option{WithColumnSeparator("  ")}, "Name  Value\n===========\na     1    \nbb    2    \n"},
+		{"box drawing", []Option{WithColumnSeparator(" │ "), WithStripe(1)}, "Name │ Value\n============\na    │ 1    \n─────┼──────\nbb   │ 2    \n"},
+		{"no padding", []Option{WithPadding(0)}, "Name|Value\n==========\na   |1    \nbb  |2    \n"},
+		{"wide padding", []Option{WithPadding(2)}, "Name  |  Value\n==============\na     |  1    \nbb    |  2    \n"},
+		{"padded separator", []Option{WithColumnSeparator(" │ "), WithPadding(0)}, "Name│Value\n==========\na   │1    \nbb  │2    \n"},
+		{"blank separator", []Option{WithColumnSeparator("  "), WithPadding(1)}, "Name  Value\n===========\na     1    \nbb    2    \n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateTableString(rows, nil, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("GenerateTableString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeparatorLine(t *testing.T) {
+	tests := []struct {
+		sep  string
+		want string
+	}{
+		{" | ", "---+----"},
+		{"  ", "-------"},
+		{" │ ", "───┼────"},
+		{"│", "──┼───"},
+		{"||", "--++---"},
+	}
+	for _, tt := range tests {
+		o := newOptions([]Option{WithColumnSeparator(tt.sep)})
+		if got := separatorLine([]int{2, 3}, o); got != tt.want {
+			t.Errorf("separatorLine(%q) = %q, want %q", tt.sep, got, tt.want)
+		}
+	}
+}
+
+// metricRow renders the selected keys of values as its columns
+type metricRow struct {
+	values map[string]string
+	err    error
+}
+
+func (r metricRow) GetHeader(fieldName string) (string, error) {
+	if fieldName == "cpu" {
+		return "CPU", nil
+	}
+	return "", nil
+}
+
+func (r metricRow) MarshalTableRow(fields []string) (map[string]string, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if fields == nil {
+		return r.values, nil
+	}
+	row := map[string]string{}
+	for _, field := range fields {
+		row[field] = r.values[field]
+	}
+	return row, nil
+}
+
+func TestTableMarshaler(t *testing.T) {
+	m := metricRow{values: map[string]string{"cpu": "12%", "mem": "3G", "disk": "9G"}}
+	tests := []struct {
+		name   string
+		rows   []TableStruct
+		fields []string
+		opts   []Option
+		want   string
+		err    string
+	}{
+		{"every field", []TableStruct{m}, nil, nil, "CPU | disk | mem\n================\n12% | 9G   | 3G \n", ""},
+		{"pointer", []TableStruct{&m}, nil, nil, "CPU | disk | mem\n================\n12% | 9G   | 3G \n", ""},
+		{"selected", []TableStruct{m}, []string{"mem", "cpu"}, nil, "mem | CPU\n=========\n3G  | 12%\n", ""},
+		{"excluded", []TableStruct{m}, []string{"*", "-disk"}, nil, "CPU | mem\n=========\n12% | 3G \n", ""},
+		{"raw headers", []TableStruct{m}, nil, []Option{WithRawFieldHeaders(true)}, "cpu | disk | mem\n================\n12% | 9G   | 3G \n", ""},
+		{"error", []TableStruct{metricRow{err: errors.New("offline")}}, nil, nil, "", "offline"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateTableString(tt.rows, tt.fields, tt.opts...)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("GenerateTableString() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("GenerateTableString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Generates a table with the rows sorted & grouped by the value of the
+// groupBy field.  A subheader with the group value is printed before the
+// rows of each group.
+func GenerateTableGrouped(tables []TableStruct, fields []string, groupBy string, opts ...Option) error {
+	o := newOptions(opts)
+	o.table = true
+
+	t, err := groupedTable(tables, fields, groupBy, o)
+	if err != nil {
+		return err
+	}
+	return generateTable(os.Stdout, t, o)
+}
+
+// groupedTable builds the table of GenerateTableGrouped.  The rows are only
+// converted once even if the group field has to be added as a column.
+func groupedTable(tables []TableStruct, fields []string, groupBy string, o *options) (*tableData, error) {
+	s, err := convertTables(tables, fields, o)
+	if err != nil {
+		return nil, err
+	}
+	t, err := s.table(fields, o)
+	if err != nil {
+		return nil, err
+	}
+
+	// the group field is added as the last column if it wasn't selected
+	groupIdx := groupColumn(t, groupBy)
+	omit := o.omitGroupColumn
+	if groupIdx < 0 {
+		if len(fields) == 0 {
+			fields = []string{"*"}
+		}
+		t, err = s.table(append(append([]string{}, fields...), groupBy), o)
+		if err != nil {
+			return nil, err
+		}
+		if groupIdx = groupColumn(t, groupBy); groupIdx < 0 {
+			return nil, fmt.Errorf("Unable to group by field '%s' which is not a column", groupBy)
+		}
+		omit = true
+	}
+	sortGroups(t, groupIdx, groupKeys(s, t.fields[groupIdx]))
+
+	t.groupHeader = t.headers[groupIdx]
+	t.groups = make([]string, len(t.rows))
+	for i, row := range t.rows {
+		t.groups[i] = row[groupIdx]
+	}
+
+	if omit {
+		t.removeColumn(groupIdx)
+	}
+	return t, nil
+}
+
+// groupKeys returns the value of the group field of each row so groups
+// sort by value, 9 before 10, instead of by their formatted string.  The
+// value is invalid for rows without the field such as nil rows.
+func groupKeys(s *tableSource, field string) []reflect.Value {
+	keys := make([]reflect.Value, len(s.items))
+	for i, item := range s.items {
+		if isNilRow(item) {
+			continue
+		}
+		if v := reflect.Indirect(reflect.ValueOf(item)); v.Kind() == reflect.Struct {
+			keys[i] = v.FieldByName(field)
+		}
+	}
+	return keys
+}
+
+// sortGroups stable sorts the rows by their group key, falling back to the
+// group column values when the keys can't be compared
+func sortGroups(t *tableData, groupIdx int, keys []reflect.Value) {
+	order := make([]int, len(t.rows))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if cmp, ok := compareValues(keys[a], keys[b]); ok {
+			return cmp < 0
+		}
+		return t.rows[a][groupIdx] < t.rows[b][groupIdx]
+	})
+
+	rows := make([][]string, len(t.rows))
+	for i, idx := range order {
+		rows[i] = t.rows[idx]
+	}
+	t.rows = rows
+	t.renumber()
+}
+
+// compareValues returns -1, 0 or 1 as a is less than, equal to or greater
+// than b.  Returns false unless both are numbers, strings, bools or times
+// of the same kind.
+func compareValues(a, b reflect.Value) (int, bool) {
+	a, b = indirectValue(a), indirectValue(b)
+	if !a.IsValid() || !b.IsValid() || a.Type() != b.Type() {
+		return 0, false
+	}
+
+	var less, greater bool
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		less, greater = a.Int() < b.Int(), a.Int() > b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		less, greater = a.Uint() < b.Uint(), a.Uint() > b.Uint()
+	case reflect.Float32, reflect.Float64:
+		less, greater = a.Float() < b.Float(), a.Float() > b.Float()
+	case reflect.String:
+		less, greater = a.String() < b.String(), a.String() > b.String()
+	case reflect.Bool:
+		less, greater = !a.Bool() && b.Bool(), a.Bool() && !b.Bool()
+	default:
+		if a.Type() != timeType || !a.CanInterface() {
+			return 0, false
+		}
+		ta, tb := a.Interface().(time.Time), b.Interface().(time.Time)
+		less, greater = ta.Before(tb), ta.After(tb)
+	}
+
+	switch {
+	case less:
+		return -1, true
+	case greater:
+		return 1, true
+	}
+	return 0, true
+}
+
+// indirectValue resolves any pointers & interfaces.  The value is invalid
+// if any of them is nil.
+func indirectValue(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// removeColumn drops the column at idx from the table
+func (t *tableData) removeColumn(idx int) {
+	t.fields = append(append([]string{}, t.fields[:idx]...), t.fields[idx+1:]...)
+	t.headers = append(t.headers[:idx], t.headers[idx+1:]...)
+	t.opts = append(t.opts[:idx], t.opts[idx+1:]...)
+	t.aligns = append(t.aligns[:idx], t.aligns[idx+1:]...)
+	if t.footer != nil {
+		t.footer = append(t.footer[:idx], t.footer[idx+1:]...)
+	}
+	for i, row := range t.rows {
+		t.rows[i] = append(row[:idx], row[idx+1:]...)
+	}
+}
+
+// groupLine is the subheader printed before each group of rows
+func groupLine(t *tableData, idx int) string {
+	return fmt.Sprintf("%s: %s", t.groupHeader, t.groups[idx])
+}
+
+// groupColumn returns the index of the column selected by groupBy or -1
+func groupColumn(t *tableData, groupBy string) int {
+	for i, field := range t.fields {
+		if field == groupBy && !(t.hasIndex && i == 0) {
+			return i
+		}
+	}
+	for i, header := range t.headers {
+		if strings.EqualFold(strings.TrimSpace(header), strings.TrimSpace(groupBy)) && !(t.hasIndex && i == 0) {
+			return i
+		}
+	}
+	return -1
+}
+
+// columnGroup returns the group of the column at idx
+func columnGroup(t *tableData, idx int, o *options) string {
+	if group, ok := o.columnGroups[t.fields[idx]]; ok {
+		return group
+	}
+	return t.opts[idx]["group"]
+}
+
+// columnGroupLine returns the line printed above the headers with the name
+// of each group of adjacent columns centered over them.  Returns false if
+// no column is in a group.
+func columnGroupLine(t *tableData, colWidth []int, o *options) (string, bool) {
+	found := false
+	spans := []string{}
+	for i := 0; i < len(colWidth); {
+		group := columnGroup(t, i, o)
+		width := colWidth[i]
+		j := i + 1
+		for group != "" && j < len(colWidth) && columnGroup(t, j, o) == group {
+			width += displayWidth(o.columnSeparator()) + colWidth[j]
+			j++
+		}
+		if group != "" {
+			found = true
+		}
+		spans = append(spans, center(truncate(group, width), width))
+		i = j
+	}
+	return strings.Join(spans, o.columnSeparator()), found
+}
+
+// center pads value on both sides to width like a centered cell
+func center(value string, width int) string {
+	return pad(value, width, AlignCenter, " ")
+}
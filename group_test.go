@@ -0,0 +1,200 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type teamRow struct {
+	Name string `header:"Name"`
+	Team int    `header:"Team"`
+}
+
+// headerCalls counts the GetHeader calls of teamRow
+var headerCalls int
+
+func (r teamRow) GetHeader(fieldName string) (string, error) {
+	headerCalls++
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func teamRows() []TableStruct {
+	return []TableStruct{
+		teamRow{Name: "a", Team: 10},
+		teamRow{Name: "b", Team: 9},
+		teamRow{Name: "c", Team: 10},
+		teamRow{Name: "d", Team: 100},
+	}
+}
+
+func TestGroupedTable(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  []string
+		groupBy string
+		opts    []Option
+		want    string
+		err     string
+	}{
+		{
+			name:    "numeric groups",
+			groupBy: "Team",
+			want:    "Name | Team\n===========\nTeam: 9\nb    |    9\nTeam: 10\na    |   10\nc    |   10\nTeam: 100\nd    |  100\n",
+		},
+		{
+			name:    "group not selected",
+			fields:  []string{"Name"},
+			groupBy: "Team",
+			want:    "Name\n====\nTeam: 9\nb   \nTeam: 10\na   \nc   \nTeam: 100\nd   \n",
+		},
+		{
+			name:    "omit group column",
+			groupBy: "Team",
+			opts:    []Option{WithOmitGroupColumn()},
+			want:    "Name\n====\nTeam: 9\nb   \nTeam: 10\na   \nc   \nTeam: 100\nd   \n",
+		},
+		{
+			name:    "index column",
+			groupBy: "Name",
+			opts:    []Option{WithIndexColumn("#")},
+			want:    "# | Name | Team\n===============\nName: a\n1 | a    |   10\nName: b\n2 | b    |    9\nName: c\n3 | c    |   10\nName: d\n4 | d    |  100\n",
+		},
+		{
+			name:    "unknown field",
+			groupBy: "Nope",
+			err:     "Invalid field 'Nope' in teamRow, did you mean 'Name'?",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newOptions(tt.opts)
+			o.table = true
+			table, err := groupedTable(teamRows(), tt.fields, tt.groupBy, o)
+			if (err == nil && tt.err != "") || (err != nil && err.Error() != tt.err) {
+				t.Fatalf("groupedTable() error = %v, want %q", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			var buf bytes.Buffer
+			if err := generateTable(&buf, table, o); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("groupedTable() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupedTableConvertsOnce(t *testing.T) {
+	headerCalls = 0
+	o := newOptions(nil)
+	o.table = true
+	if _, err := groupedTable(teamRows(), []string{"Name"}, "Team", o); err != nil {
+		t.Fatal(err)
+	}
+	// one call per field of each row
+	if headerCalls != 8 {
+		t.Errorf("GetHeader called %d times, want 8", headerCalls)
+	}
+}
+
+func TestCompareValues(t *testing.T) {
+	one, two := 1, 2
+	tests := []struct {
+		a, b interface{}
+		cmp  int
+		ok   bool
+	}{
+		{9, 10, -1, true},
+		{uint8(10), uint8(9), 1, true},
+		{1.5, 1.5, 0, true},
+		{"b", "a", 1, true},
+		{false, true, -1, true},
+		{&one, &two, -1, true},
+		{(*int)(nil), &two, 0, false},
+		{1, "1", 0, false},
+		{[]int{1}, []int{2}, 0, false},
+		{time.Unix(2, 0), time.Unix(1, 0), 1, true},
+	}
+	for _, tt := range tests {
+		cmp, ok := compareValues(reflect.ValueOf(tt.a), reflect.ValueOf(tt.b))
+		if cmp != tt.cmp || ok != tt.ok {
+			t.Errorf("compareValues(%v, %v) = %d, %t, want %d, %t", tt.a, tt.b, cmp, ok, tt.cmp, tt.ok)
+		}
+	}
+}
+
+type trafficRow struct {
+	Host    string `header:"Host"`
+	RxBytes int    `header:"Bytes,group=RX"`
+	RxPkts  int    `header:"Packets,group=RX"`
+	TxBytes int    `header:"Bytes,group=TX"`
+	TxPkts  int    `header:"Pkts,group=TX"`
+}
+
+func (r trafficRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestColumnGroups(t *testing.T) {
+	rows := []TableStruct{trafficRow{"a", 1, 2, 3, 4}}
+	tests := []struct {
+		name   string
+		fields []string
+		opts   []Option
+		want   string
+	}{
+		{
+			name: "tag",
+			want: "     |       RX        |      TX     \nHost | Bytes | Packets | Bytes | Pkts\n=====================================\na    |     1 |       2 |     3 |    4\n",
+		},
+		{
+			name:   "only adjacent columns share a group",
+			fields: []string{"Host", "RxBytes", "TxBytes", "RxPkts"},
+			want:   "     |  RX   |  TX   |   RX   \nHost | Bytes | Bytes | Packets\n==============================\na    |     1 |     3 |       2\n",
+		},
+		{
+			name:   "option wins and names are truncated",
+			fields: []string{"Host", "TxPkts"},
+			opts:   []Option{WithColumnGroups(map[string]string{"Host": "Machine", "TxPkts": "Sent"})},
+			want:   "Mach | Sent\nHost | Pkts\n===========\na    |    4\n",
+		},
+		{
+			name:   "no groups",
+			fields: []string{"Host"},
+			want:   "Host\n====\na   \n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateTableString(rows, tt.fields, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("GenerateTableString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
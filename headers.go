@@ -0,0 +1,207 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// HeaderCase selects how the case of headers is transformed
+type HeaderCase int
+
+const (
+	// HeaderCaseAsIs leaves headers unchanged
+	HeaderCaseAsIs HeaderCase = iota
+	// HeaderCaseUpper converts headers to UPPER CASE
+	HeaderCaseUpper
+	// HeaderCaseLower converts headers to lower case
+	HeaderCaseLower
+	// HeaderCaseTitle upper cases the first letter of each word
+	HeaderCaseTitle
+)
+
+// apply returns header in the selected case
+func (c HeaderCase) apply(header string) string {
+	switch c {
+	case HeaderCaseUpper:
+		return strings.ToUpper(header)
+	case HeaderCaseLower:
+		return strings.ToLower(header)
+	case HeaderCaseTitle:
+		words := strings.Split(header, " ")
+		for i, word := range words {
+			r, size := utf8.DecodeRuneInString(word)
+			if size > 0 {
+				words[i] = string(unicode.ToTitle(r)) + word[size:]
+			}
+		}
+		return strings.Join(words, " ")
+	}
+	return header
+}
+
+// FieldHeader is a struct field name and its header
+type FieldHeader struct {
+	Field  string
+	Header string
+}
+
+// GetHeaders returns the fields of the struct (or pointer to struct) v
+// and their headers in the order of the order tag option, then declaration
+// order.  Unexported and excluded fields are skipped.  Works on the zero value of the type.
+func GetHeaders(v interface{}, opts ...Option) ([]FieldHeader, error) {
+	o := newOptions(opts)
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return []FieldHeader{}, fmt.Errorf("Invalid type %v is not a struct", reflect.TypeOf(v))
+	}
+
+	fields, err := orderedFields(t, o)
+	if err != nil {
+		return []FieldHeader{}, err
+	}
+	ret := []FieldHeader{}
+	for _, f := range fields {
+		header, _, err := parseFieldTag(t, f)
+		if err != nil {
+			return ret, err
+		}
+		if header == "" {
+			header = o.fallbackHeader(f)
+		}
+		ret = append(ret, FieldHeader{Field: f.Name, Header: header})
+	}
+	return ret, nil
+}
+
+// visibleFields returns the fields of the struct type t which can be
+// rendered in declaration order
+func visibleFields(t reflect.Type, o *options) []reflect.StructField {
+	fields := []reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || o.isExcluded(f) {
+			continue // skip unexported & excluded fields
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// orderedFields returns the visible fields of t sorted by the order tag
+// option, such as `header:"Name,order=1"`.  Fields with the same order
+// and fields without the order tag option follow in declaration order.
+func orderedFields(t reflect.Type, o *options) ([]reflect.StructField, error) {
+	type orderedField struct {
+		field  reflect.StructField
+		order  int
+		tagged bool
+	}
+
+	fields := []orderedField{}
+	for _, f := range visibleFields(t, o) {
+		of := orderedField{field: f}
+		_, opts, err := parseFieldTag(t, f)
+		if err != nil {
+			return []reflect.StructField{}, err
+		}
+		if opts.has("order") {
+			if of.order, err = strconv.Atoi(opts["order"]); err != nil {
+				return []reflect.StructField{}, fmt.Errorf("Invalid order '%s' for field '%s' in %s", opts["order"], f.Name, t.Name())
+			}
+			of.tagged = true
+		}
+		fields = append(fields, of)
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		if fields[i].tagged != fields[j].tagged {
+			return fields[i].tagged
+		}
+		return fields[i].order < fields[j].order
+	})
+
+	ret := make([]reflect.StructField, len(fields))
+	for i, of := range fields {
+		ret[i] = of.field
+	}
+	return ret, nil
+}
+
+// checkHeaderOverrides returns an error if WithHeaderOverrides names a
+// field which is not in headers
+func checkHeaderOverrides(rowType reflect.Type, headers map[string]string, o *options) error {
+	if rowType == nil {
+		return nil // no rows to check against
+	}
+	fields := make([]string, 0, len(o.headerOverrides))
+	for field := range o.headerOverrides {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		if _, ok := headers[field]; !ok {
+			return fmt.Errorf("Invalid header override for unknown field '%s' in %s", field, rowType.Name())
+		}
+	}
+	return nil
+}
+
+// CheckHeaders returns an error if two fields of the struct (or pointer to
+// struct) v have the same header.  Headers which only differ by case are
+// duplicates when WithCaseInsensitiveHeaders is set.
+func CheckHeaders(v interface{}, opts ...Option) error {
+	o := newOptions(opts)
+	headers, err := GetHeaders(v, opts...)
+	if err != nil {
+		return err
+	}
+	fields := make([]string, len(headers))
+	names := make([]string, len(headers))
+	for i, fh := range headers {
+		fields[i] = fh.Field
+		names[i] = fh.Header
+	}
+	return checkDuplicateHeaders(fields, names, o)
+}
+
+// checkDuplicateHeaders returns an error listing the first header used by
+// more than one field
+func checkDuplicateHeaders(fields, headers []string, o *options) error {
+	for i, header := range headers {
+		dups := []string{fields[i]}
+		for j := i + 1; j < len(headers); j++ {
+			if headers[j] == header || (o.caseInsensitiveHeaders && strings.EqualFold(headers[j], header)) {
+				dups = append(dups, fields[j])
+			}
+		}
+		if len(dups) > 1 {
+			return fmt.Errorf("Duplicate header '%s' for fields: %s", header, strings.Join(dups, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+type dupRow struct {
+	Name  string `header:"Name"`
+	Label string `header:"Name"`
+	Other string `header:"name"`
+}
+
+func (r dupRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+type caseRow struct {
+	Name  string `header:"Name"`
+	Other string `header:"NAME"`
+}
+
+func (r caseRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestCheckHeaders(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		opts []Option
+		err  string
+	}{
+		{"unique", colorRow{}, nil, ""},
+		{"duplicate", dupRow{}, nil, "Duplicate header 'Name' for fields: Name, Label"},
+		{"pointer", &dupRow{}, nil, "Duplicate header 'Name' for fields: Name, Label"},
+		{"case sensitive", caseRow{}, nil, ""},
+		{"case insensitive", caseRow{}, []Option{WithCaseInsensitiveHeaders()}, "Duplicate header 'Name' for fields: Name, Other"},
+		{"case insensitive duplicate", dupRow{}, []Option{WithCaseInsensitiveHeaders()}, "Duplicate header 'Name' for fields: Name, Label, Other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckHeaders(tt.v, tt.opts...)
+			if (err == nil && tt.err != "") || (err != nil && err.Error() != tt.err) {
+				t.Errorf("CheckHeaders() = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestUniqueHeaders(t *testing.T) {
+	_, err := GenerateTableString([]TableStruct{dupRow{}}, nil, WithUniqueHeaders())
+	if want := "Duplicate header 'Name' for fields: Name, Label"; err == nil || err.Error() != want {
+		t.Errorf("GenerateTableString() error = %v, want %q", err, want)
+	}
+	if _, err := GenerateTableString([]TableStruct{dupRow{}}, nil); err != nil {
+		t.Errorf("GenerateTableString() error = %v, want nil", err)
+	}
+}
+
+type cjkRow struct {
+	Name string `header:"名前"`
+	City string `header:"Café"`
+}
+
+func (r cjkRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestHeaderRuleWidth(t *testing.T) {
+	tests := []struct {
+		row  cjkRow
+		want string
+	}{
+		{cjkRow{Name: "a", City: "b"}, "名前 | Café\n===========\na    | b   \n"},
+		{cjkRow{Name: "太郎", City: "Zürich"}, "名前 | Café  \n=============\n太郎 | Zürich\n"},
+	}
+	for _, tt := range tests {
+		got, err := GenerateTableString([]TableStruct{tt.row}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("GenerateTableString() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestHeaderRule(t *testing.T) {
+	rows := []TableStruct{colorRow{Name: "a", Value: "b"}}
+	tests := []struct {
+		name string
+		rule string
+		want string
+	}{
+		{"dash", "-", "Name | Value\n------------\na    | b    \n"},
+		{"box drawing", "─", "Name | Value\n────────────\na    | b    \n"},
+		{"none", "", "Name | Value\na    | b    \n"},
+		{"repeated", "=-", "Name | Value\n=-=-=-=-=-=-\na    | b    \n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateTableString(rows, nil, WithHeaderRule(tt.rule))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("GenerateTableString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeaderCase(t *testing.T) {
+	tests := []struct {
+		c      HeaderCase
+		header string
+		want   string
+	}{
+		{HeaderCaseAsIs, "user id", "user id"},
+		{HeaderCaseUpper, "User id", "USER ID"},
+		{HeaderCaseLower, "User ID", "user id"},
+		{HeaderCaseTitle, "user id", "User Id"},
+		{HeaderCaseTitle, "élan  vital", "Élan  Vital"},
+	}
+	for _, tt := range tests {
+		if got := tt.c.apply(tt.header); got != tt.want {
+			t.Errorf("HeaderCase(%d).apply(%q) = %q, want %q", tt.c, tt.header, got, tt.want)
+		}
+	}
+
+	rows := []TableStruct{colorRow{Name: "a", Value: "b"}}
+	outputs := []struct {
+		format Format
+		want   string
+	}{
+		{FormatTable, "NAME | VALUE\n============\na    | b    \n"},
+		// CSV headers are left as is
+		{FormatCSV, "Name,Value\na,b\n"},
+	}
+	for _, tt := range outputs {
+		var buf bytes.Buffer
+		err := RenderMulti(rows, nil, []Output{{Format: tt.format, Writer: &buf}}, WithHeaderCase(HeaderCaseUpper), WithCSVHeader())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("RenderMulti(%d) = %q, want %q", tt.format, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestHeaderOverrides(t *testing.T) {
+	rows := []TableStruct{colorRow{Name: "a", Value: "b"}}
+	tests := []struct {
+		name      string
+		format    Format
+		overrides map[string]string
+		opts      []Option
+		want      string
+		err       string
+	}{
+		{"table", FormatTable, map[string]string{"Value": "Setting"}, nil, "Name | Setting\n==============\na    | b      \n", ""},
+		{"csv", FormatCSV, map[string]string{"Name": "Host"}, []Option{WithCSVHeader()}, "Host,Value\na,b\n", ""},
+		{"not changed by header case", FormatTable, map[string]string{"Name": "host"}, []Option{WithHeaderCase(HeaderCaseUpper)}, "host | VALUE\n============\na    | b    \n", ""},
+		{"unknown field", FormatTable, map[string]string{"Nope": "x"}, nil, "", "Invalid header override for unknown field 'Nope' in colorRow"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			opts := append([]Option{WithHeaderOverrides(tt.overrides)}, tt.opts...)
+			err := RenderMulti(rows, nil, []Output{{Format: tt.format, Writer: &buf}}, opts...)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("RenderMulti() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("RenderMulti() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRawFieldHeaders(t *testing.T) {
+	rows := []TableStruct{statusRow{OK: "y", Count: 1, Name: "a"}}
+	derived := WithDerivedColumn("D", "Derived", func(TableStruct) string { return "x" })
+	tests := []struct {
+		name   string
+		format Format
+		opts   []Option
+		want   string
+	}{
+		{"off", FormatTable, []Option{WithRawFieldHeaders(false)}, "OK   |   N | Name\n=================\ny    |   1 | a   \n"},
+		{"on", FormatTable, []Option{WithRawFieldHeaders(true)}, "OK   | Count | Name\n===================\ny    |     1 | a   \n"},
+		{"csv", FormatCSV, []Option{WithRawFieldHeaders(true), WithCSVHeader()}, "OK,Count,Name\ny,1,a\n"},
+		{"ignores header case", FormatTable, []Option{WithRawFieldHeaders(true), WithHeaderCase(HeaderCaseUpper)}, "OK   | Count | Name\n===================\ny    |     1 | a   \n"},
+		{"derived", FormatTable, []Option{WithRawFieldHeaders(true), derived}, "OK   | Count | Name | D\n=======================\ny    |     1 | a    | x\n"},
+		// explicit overrides still apply
+		{"overrides", FormatTable, []Option{WithRawFieldHeaders(true), WithHeaderOverrides(map[string]string{"Count": "#"})}, "OK   |   # | Name\n=================\ny    |   1 | a   \n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := RenderMulti(rows, nil, []Output{{Format: tt.format, Writer: &buf}}, tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("RenderMulti() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// characters with a special meaning in LaTeX
+var latexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`&`, `\&`,
+	`%`, `\%`,
+	`$`, `\$`,
+	`#`, `\#`,
+	`_`, `\_`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`~`, `\textasciitilde{}`,
+	`^`, `\textasciicircum{}`,
+)
+
+// Generates a LaTeX tabular environment.  The column spec follows the
+// alignment of each column.
+func GenerateLaTeX(w io.Writer, tables []TableStruct, fields []string, opts ...Option) error {
+	t, err := buildTable(tables, fields, newOptions(opts))
+	if err != nil {
+		return err
+	}
+	return generateLaTeX(w, t)
+}
+
+func generateLaTeX(w io.Writer, t *tableData) error {
+	spec := make([]string, len(t.fields))
+	for i := range spec {
+		spec[i] = latexAlign(alignAt(t.aligns, i))
+	}
+
+	lines := []string{
+		fmt.Sprintf("\\begin{tabular}{%s}", strings.Join(spec, "")),
+		"\\hline",
+		latexRow(t.headers),
+		"\\hline",
+	}
+	for _, row := range t.rows {
+		lines = append(lines, latexRow(row))
+	}
+	if t.footer != nil {
+		lines = append(lines, "\\hline", latexRow(t.footer))
+	}
+	lines = append(lines, "\\hline", "\\end{tabular}")
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// latexRow returns the escaped & separated cells terminated by \\
+func latexRow(values []string) string {
+	cells := make([]string, len(values))
+	for i, value := range values {
+		cells[i] = latexEscaper.Replace(value)
+	}
+	return strings.Join(cells, " & ") + ` \\`
+}
+
+// latexAlign returns the column spec for align
+func latexAlign(align Align) string {
+	switch align {
+	case AlignRight, AlignDecimal:
+		return "r"
+	case AlignCenter:
+		return "c"
+	}
+	return "l"
+}
@@ -0,0 +1,56 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGenerateLaTeX(t *testing.T) {
+	tests := []struct {
+		name string
+		rows []TableStruct
+		want string
+	}{
+		{
+			name: "plain",
+			rows: []TableStruct{colorRow{Name: "a", Value: "b"}},
+			want: "\\begin{tabular}{ll}\n\\hline\nName & Value \\\\\n\\hline\na & b \\\\\n\\hline\n\\end{tabular}\n",
+		},
+		{
+			name: "aligned and escaped",
+			rows: []TableStruct{receiptRow{"a_b & c", 3.5, 1}, receiptRow{`50% {x}~^\`, 12.25, 10}},
+			want: "\\begin{tabular}{lrc}\n\\hline\nItem & Price & Qty \\\\\n\\hline\n" +
+				"a\\_b \\& c & 3.5 & 1 \\\\\n" +
+				"50\\% \\{x\\}\\textasciitilde{}\\textasciicircum{}\\textbackslash{} & 12.25 & 10 \\\\\n" +
+				"\\hline\n\\end{tabular}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := GenerateLaTeX(&buf, tt.rows, nil); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("GenerateLaTeX() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
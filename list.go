@@ -0,0 +1,99 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// isListElem returns true if slices & arrays of t can be rendered as a
+// list of values
+func isListElem(t reflect.Type) bool {
+	if lookupTypeFormatter(t) != nil || t == timeType {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128,
+		reflect.Interface:
+		return true
+	case reflect.Ptr:
+		return isListElem(t.Elem())
+	}
+	return false
+}
+
+// formatList renders each element of a slice or array joined by the
+// list separator
+func formatList(v reflect.Value, fi *fieldInfo, o *options) (string, error) {
+	sep := o.listSep
+	if fi.opts.has("sep") {
+		sep = fi.opts["sep"]
+	}
+
+	values := make([]string, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		val, err := formatValue(v.Index(i), fi, o)
+		if err != nil {
+			return "", err
+		}
+		values[i] = val
+	}
+	return strings.Join(values, sep), nil
+}
+
+// formatByteList renders a []byte or [N]byte.  Slices default to a string
+// and arrays to hex.
+func formatByteList(v reflect.Value, fi *fieldInfo, o *options) (string, error) {
+	b := make([]byte, v.Len())
+	for i := range b {
+		b[i] = byte(v.Index(i).Uint())
+	}
+
+	if fi.opts.has("uuid") && len(b) == 16 {
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+	}
+
+	enc := o.byteEncoding
+	if enc == "" && v.Kind() == reflect.Array {
+		enc = "hex"
+	}
+	if fi.opts.has("enc") {
+		enc = fi.opts["enc"]
+	}
+	return formatBytes(b, enc)
+}
+
+// formatBytes renders b as a string, hex or base64
+func formatBytes(b []byte, enc string) (string, error) {
+	switch enc {
+	case "", "string":
+		return string(b), nil
+	case "hex":
+		return hex.EncodeToString(b), nil
+	case "base64":
+		return base64.StdEncoding.EncodeToString(b), nil
+	}
+	return "", fmt.Errorf("unknown byte encoding '%s'", enc)
+}
@@ -0,0 +1,138 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type bytesRow struct {
+	Data []byte `header:"Data"`
+	Hash []byte `header:"Hash,enc=hex"`
+}
+
+func (r bytesRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestByteEncoding(t *testing.T) {
+	row := bytesRow{Data: []byte("hi!"), Hash: []byte{0xde, 0xad}}
+	tests := []struct {
+		name string
+		opts []Option
+		want map[string]string
+		err  string
+	}{
+		{"default", nil, map[string]string{"Data": "hi!", "Hash": "dead"}, ""},
+		{"string", []Option{WithByteEncoding("string")}, map[string]string{"Data": "hi!", "Hash": "dead"}, ""},
+		{"hex", []Option{WithByteEncoding("hex")}, map[string]string{"Data": "686921", "Hash": "dead"}, ""},
+		{"base64", []Option{WithByteEncoding("base64")}, map[string]string{"Data": "aGkh", "Hash": "dead"}, ""},
+		{"unknown", []Option{WithByteEncoding("rot13")}, nil, "unknown byte encoding 'rot13'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := TableRow(row, tt.opts...)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("TableRow() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TableRow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type arrayRow struct {
+	Digest [4]byte   `header:"Digest"`
+	ID     [16]byte  `header:"ID,uuid"`
+	Raw    [3]byte   `header:"Raw,enc=string"`
+	Ports  [3]int    `header:"Ports"`
+	Names  []string  `header:"Names,sep= "`
+	Ptrs   []*int    `header:"Ptrs"`
+	Nested [][]int   `header:"Nested"`
+	Empty  [0]string `header:"Empty"`
+}
+
+func (r arrayRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestListFields(t *testing.T) {
+	one := 1
+	row := arrayRow{
+		Digest: [4]byte{0xca, 0xfe, 0xba, 0xbe},
+		ID:     [16]byte{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0, 0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0},
+		Raw:    [3]byte{'a', 'b', 'c'},
+		Ports:  [3]int{22, 80, 443},
+		Names:  []string{"a", "b"},
+		Ptrs:   []*int{&one, nil},
+		Nested: [][]int{{1}},
+	}
+	tests := []struct {
+		name string
+		opts []Option
+		want map[string]string
+	}{
+		{
+			name: "default",
+			want: map[string]string{
+				"Digest": "cafebabe",
+				"ID":     "12345678-9abc-def0-1234-56789abcdef0",
+				"Raw":    "abc",
+				"Ports":  "22,80,443",
+				"Names":  "a b",
+				"Ptrs":   "1,",
+				"Nested": "NO_SUPPORT",
+				"Empty":  "",
+			},
+		},
+		{
+			name: "list separator",
+			opts: []Option{WithListSeparator(" | "), WithByteEncoding("base64"), WithNullPlaceholder("-")},
+			want: map[string]string{
+				"Digest": "yv66vg==",
+				"ID":     "12345678-9abc-def0-1234-56789abcdef0",
+				"Raw":    "abc",
+				"Ports":  "22 | 80 | 443",
+				"Names":  "a b",
+				"Ptrs":   "1 | -",
+				"Nested": "NO_SUPPORT",
+				"Empty":  "",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := TableRow(row, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TableRow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
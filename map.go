@@ -0,0 +1,81 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// formatMap renders a map as key=value pairs sorted by key
+func formatMap(v reflect.Value, fi *fieldInfo, o *options) (string, error) {
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return lessValue(keys[i], keys[j])
+	})
+
+	pairSep := o.mapPairSep
+	if fi.opts.has("sep") {
+		pairSep = fi.opts["sep"]
+	}
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		k, err := formatValue(key, fi, o)
+		if err != nil {
+			return "", err
+		}
+		val, err := formatValue(v.MapIndex(key), fi, o)
+		if err != nil {
+			return "", err
+		}
+		pairs[i] = k + o.mapKeySep + val
+	}
+	return strings.Join(pairs, pairSep), nil
+}
+
+// lessValue orders numeric values numerically and everything else by
+// its string representation
+func lessValue(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	case reflect.String:
+		return a.String() < b.String()
+	case reflect.Bool:
+		return !a.Bool() && b.Bool()
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() && !b.IsNil()
+		}
+		if a.Elem().Kind() != b.Elem().Kind() {
+			return a.Elem().Kind() < b.Elem().Kind()
+		}
+		return lessValue(a.Elem(), b.Elem())
+	}
+	if a.CanInterface() && b.CanInterface() {
+		return fmt.Sprint(a.Interface()) < fmt.Sprint(b.Interface())
+	}
+	return false
+}
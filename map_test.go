@@ -0,0 +1,83 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"reflect"
+	"testing"
+)
+
+type mapRow struct {
+	Labels map[string]string      `header:"Labels"`
+	Ports  map[int]bool           `header:"Ports,sep=; "`
+	Any    map[interface{}]string `header:"Any"`
+}
+
+func (r mapRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestMapFields(t *testing.T) {
+	row := mapRow{
+		Labels: map[string]string{"zone": "b", "app": "web", "env": "prod"},
+		Ports:  map[int]bool{443: true, 80: false, 8080: true},
+		Any:    map[interface{}]string{"b": "s", 2: "i", nil: "n", 1: "i"},
+	}
+	tests := []struct {
+		name string
+		row  mapRow
+		opts []Option
+		want map[string]string
+	}{
+		{
+			name: "sorted",
+			row:  row,
+			want: map[string]string{
+				"Labels": "app=web,env=prod,zone=b",
+				"Ports":  "80=false; 443=true; 8080=true",
+				"Any":    "=n,1=i,2=i,b=s",
+			},
+		},
+		{
+			name: "separators",
+			row:  row,
+			opts: []Option{WithMapSeparators(" ", ":")},
+			want: map[string]string{
+				"Labels": "app:web env:prod zone:b",
+				"Ports":  "80:false; 443:true; 8080:true",
+				"Any":    ":n 1:i 2:i b:s",
+			},
+		},
+		{
+			name: "empty",
+			row:  mapRow{},
+			want: map[string]string{"Labels": "", "Ports": "", "Any": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := TableRow(tt.row, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TableRow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
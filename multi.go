@@ -0,0 +1,110 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Format is an output format of RenderMulti
+type Format int
+
+const (
+	FormatTable Format = iota
+	FormatCSV
+	FormatVertical
+	FormatTransposed
+	FormatYAML
+	FormatLaTeX
+	FormatConfluence
+	FormatOrg
+)
+
+// Output is a format & the writer RenderMulti writes it to
+type Output struct {
+	Format Format
+	Writer io.Writer
+}
+
+// RenderMulti renders the rows in the format of each output, converting
+// them via TableRow only once.  Stops at the first output which fails
+// unless WithCollectErrors is set.
+func RenderMulti(tables []TableStruct, fields []string, outputs []Output, opts ...Option) error {
+	// WithCSVAbsoluteTime renders reltime fields differently for CSV
+	sources := map[bool]*tableSource{}
+	errs := []string{}
+	for _, out := range outputs {
+		o := newOptions(opts)
+		switch out.Format {
+		case FormatTable, FormatVertical, FormatTransposed, FormatOrg:
+			o.table = true
+		case FormatCSV:
+			o.csv = true
+		}
+
+		absolute := o.csv && o.csvAbsoluteTime
+		s, ok := sources[absolute]
+		if !ok {
+			var err error
+			if s, err = convertTables(tables, fields, o); err != nil {
+				return err
+			}
+			sources[absolute] = s
+		}
+
+		if err := renderOutput(out, s, fields, o); err != nil {
+			if !o.collectErrors {
+				return err
+			}
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("Unable to render %d outputs: %s", len(errs), strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// renderOutput writes the table built from s in the format of out
+func renderOutput(out Output, s *tableSource, fields []string, o *options) error {
+	t, err := s.table(fields, o)
+	if err != nil {
+		return err
+	}
+	switch out.Format {
+	case FormatTable:
+		return generateTable(out.Writer, t, o)
+	case FormatCSV:
+		return generateCSV(out.Writer, t, o)
+	case FormatVertical:
+		return generateVertical(out.Writer, t)
+	case FormatTransposed:
+		return generateTable(out.Writer, transpose(t), o)
+	case FormatYAML:
+		return generateYAML(out.Writer, t)
+	case FormatLaTeX:
+		return generateLaTeX(out.Writer, t)
+	case FormatConfluence:
+		return generateConfluence(out.Writer, t)
+	case FormatOrg:
+		return generateOrg(out.Writer, t)
+	}
+	return fmt.Errorf("Invalid output format %d", out.Format)
+}
@@ -0,0 +1,162 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var errDiskFull = errors.New("disk full")
+
+// failWriter fails every write
+type failWriter struct{}
+
+func (failWriter) Write(p []byte) (int, error) {
+	return 0, errDiskFull
+}
+
+func multiRows() []TableStruct {
+	return []TableStruct{
+		colorRow{Name: "a", Value: "1"},
+		colorRow{Name: "b", Value: "2"},
+	}
+}
+
+func TestRenderMultiWriteError(t *testing.T) {
+	formats := []Format{FormatTable, FormatCSV, FormatVertical, FormatTransposed, FormatYAML, FormatLaTeX, FormatConfluence, FormatOrg}
+	for _, format := range formats {
+		err := RenderMulti(multiRows(), nil, []Output{{Format: format, Writer: failWriter{}}})
+		if !errors.Is(err, errDiskFull) {
+			t.Errorf("RenderMulti(%d) = %v, want %v", format, err, errDiskFull)
+		}
+	}
+}
+
+func TestRenderMultiCollectErrors(t *testing.T) {
+	var buf bytes.Buffer
+	outputs := []Output{
+		{Format: FormatTable, Writer: failWriter{}},
+		{Format: FormatCSV, Writer: &buf},
+		{Format: FormatTransposed, Writer: failWriter{}},
+	}
+	err := RenderMulti(multiRows(), nil, outputs, WithCollectErrors())
+	if err == nil || !strings.Contains(err.Error(), "2 outputs") {
+		t.Errorf("RenderMulti() = %v, want both failed outputs", err)
+	}
+	if buf.String() != "a,1\nb,2\n" {
+		t.Errorf("CSV output = %q", buf.String())
+	}
+
+	// fail fast stops at the first output
+	buf.Reset()
+	if err := RenderMulti(multiRows(), nil, outputs); !errors.Is(err, errDiskFull) {
+		t.Errorf("RenderMulti() = %v, want %v", err, errDiskFull)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("CSV output = %q, want nothing", buf.String())
+	}
+}
+
+func TestGenerateTransposedWriteError(t *testing.T) {
+	if err := GenerateTransposed(failWriter{}, multiRows(), nil); !errors.Is(err, errDiskFull) {
+		t.Errorf("GenerateTransposed() = %v, want %v", err, errDiskFull)
+	}
+}
+
+func TestTableWriterFlushWriteError(t *testing.T) {
+	tw := NewTableWriter(failWriter{}, nil)
+	for _, row := range multiRows() {
+		if err := tw.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Flush(); !errors.Is(err, errDiskFull) {
+		t.Errorf("Flush() = %v, want %v", err, errDiskFull)
+	}
+}
+
+// limitWriter accepts the first n bytes and fails every write after that
+type limitWriter struct {
+	n       int
+	written int
+}
+
+func (w *limitWriter) Write(p []byte) (int, error) {
+	if w.written+len(p) > w.n {
+		return 0, errDiskFull
+	}
+	w.written += len(p)
+	return len(p), nil
+}
+
+func TestCSVFlushError(t *testing.T) {
+	// large enough that csv.Writer writes a full buffer before the final flush
+	rows := make([]TableStruct, 1000)
+	for i := range rows {
+		rows[i] = colorRow{Name: "name", Value: "value"}
+	}
+	tests := []struct {
+		name string
+		w    *limitWriter
+		err  error
+	}{
+		{"ok", &limitWriter{n: 1 << 20}, nil},
+		{"final flush", &limitWriter{n: 4096}, errDiskFull},
+		{"first write", &limitWriter{n: 0}, errDiskFull},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RenderMulti(rows, nil, []Output{{Format: FormatCSV, Writer: tt.w}})
+			if !errors.Is(err, tt.err) {
+				t.Errorf("RenderMulti() = %v, want %v", err, tt.err)
+			}
+			if tt.err != nil && tt.w.n > 0 && tt.w.written == 0 {
+				t.Errorf("RenderMulti() wrote nothing before the final flush")
+			}
+		})
+	}
+}
+
+func TestCSVTrailer(t *testing.T) {
+	rows := []TableStruct{colorRow{Name: "a", Value: "b"}, colorRow{Name: "c", Value: "d"}}
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{"none", []Option{WithCSVHeader()}, "Name,Value\na,b\nc,d\n"},
+		{"count", []Option{WithCSVTrailer(false)}, "a,b\nc,d\n#rows=2\n"},
+		// sha256 of "Name,Value\na,b\nc,d\n"
+		{"checksum", []Option{WithCSVHeader(), WithCSVTrailer(true)},
+			"Name,Value\na,b\nc,d\n#rows=2,sha256=3f7fad458ac6c14595699d1db789443fa6fa764b0b29848dd2e5f0293c638dfa\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := RenderMulti(rows, nil, []Output{{Format: FormatCSV, Writer: &buf}}, tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("RenderMulti() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// prefixes for integers rendered with the prefix tag option
+var basePrefix = map[int]string{
+	2:  "0b",
+	8:  "0o",
+	16: "0x",
+}
+
+// formatInt renders n in the base set via the base tag option
+func formatInt(n int64, fi *fieldInfo) string {
+	if n < 0 {
+		return "-" + formatUint(uint64(-n), fi)
+	}
+	return formatUint(uint64(n), fi)
+}
+
+// formatUint renders n in the base set via the base tag option
+func formatUint(n uint64, fi *fieldInfo) string {
+	if fi.base == 0 || fi.base == 10 {
+		return strconv.FormatUint(n, 10)
+	}
+	ret := strconv.FormatUint(n, fi.base)
+	if fi.opts.has("prefix") {
+		ret = basePrefix[fi.base] + ret
+	}
+	return ret
+}
+
+// formatFloat renders f as the shortest representation which round trips.
+// Like encoding/json it is plain decimal, so large integral values such as
+// byte counts read naturally, unless the magnitude is below 1e-6 or at
+// least 1e21 where the exponent form is used: 1e+22
+func formatFloat(f float64, bitSize int) string {
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		return strconv.FormatFloat(f, 'e', -1, bitSize)
+	}
+	return strconv.FormatFloat(f, 'f', -1, bitSize)
+}
+
+// formatComplex renders c as (real+imaginary i) with both parts formatted
+// like formatFloat.  bitSize is 64 for complex64 & 128 for complex128.
+func formatComplex(c complex128, bitSize int) string {
+	im := formatFloat(imag(c), bitSize/2)
+	if !strings.HasPrefix(im, "-") && !strings.HasPrefix(im, "+") {
+		im = "+" + im
+	}
+	return "(" + formatFloat(real(c), bitSize/2) + im + "i)"
+}
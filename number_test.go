@@ -0,0 +1,158 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+type floatRow struct {
+	F32 float32 `header:"F32"`
+	F64 float64 `header:"F64"`
+}
+
+func (r floatRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestFormatFloat(t *testing.T) {
+	tests := []struct {
+		f    float64
+		want string
+	}{
+		{0, "0"},
+		{1.5, "1.5"},
+		{-2.25, "-2.25"},
+		{1234567, "1234567"},
+		{1e20, "100000000000000000000"},
+		{1e21, "1e+21"},
+		{1e22, "1e+22"},
+		{-1.5e300, "-1.5e+300"},
+		{0.0001, "0.0001"},
+		{0.000001, "0.000001"},
+		{0.0000001, "1e-07"},
+		{1.25e-10, "1.25e-10"},
+		{math.NaN(), "NaN"},
+		{math.Inf(1), "+Inf"},
+		{math.Inf(-1), "-Inf"},
+	}
+	for _, tt := range tests {
+		got := formatFloat(tt.f, 64)
+		if got != tt.want {
+			t.Errorf("formatFloat(%v) = %q, want %q", tt.f, got, tt.want)
+		}
+	}
+}
+
+func TestFloatFields(t *testing.T) {
+	tests := []struct {
+		row  floatRow
+		want map[string]string
+	}{
+		{floatRow{F32: 0.1, F64: 0.1}, map[string]string{"F32": "0.1", "F64": "0.1"}},
+		{floatRow{F32: 1e22, F64: 1e22}, map[string]string{"F32": "1e+22", "F64": "1e+22"}},
+		{floatRow{F32: 1e-7, F64: 1e-7}, map[string]string{"F32": "1e-07", "F64": "1e-07"}},
+		{floatRow{F32: 16777216, F64: 123456789.5}, map[string]string{"F32": "16777216", "F64": "123456789.5"}},
+	}
+	for _, tt := range tests {
+		row, _, err := TableRow(tt.row)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(row, tt.want) {
+			t.Errorf("TableRow(%v) = %v, want %v", tt.row, row, tt.want)
+		}
+	}
+}
+
+func TestFormatComplex(t *testing.T) {
+	tests := []struct {
+		c    complex128
+		want string
+	}{
+		{complex(1e22, 1e-7), "(1e+22+1e-07i)"},
+		{complex(-1234567, -0.5), "(-1234567-0.5i)"},
+	}
+	for _, tt := range tests {
+		if got := formatComplex(tt.c, 128); got != tt.want {
+			t.Errorf("formatComplex(%v) = %q, want %q", tt.c, got, tt.want)
+		}
+	}
+}
+
+type baseRow struct {
+	Hex    uint32 `header:"Hex,base=16,prefix"`
+	Octal  int    `header:"Octal,base=8"`
+	Binary int8   `header:"Binary,base=2,prefix"`
+	Base36 uint64 `header:"Base36,base=36"`
+	Dec    int    `header:"Dec,base=10,prefix"`
+}
+
+func (r baseRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+type badBaseRow struct {
+	N int `header:"N,base=37"`
+}
+
+func (r badBaseRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestBaseFormatting(t *testing.T) {
+	tests := []struct {
+		name string
+		row  baseRow
+		want map[string]string
+	}{
+		{
+			name: "positive",
+			row:  baseRow{Hex: 255, Octal: 8, Binary: 5, Base36: 35, Dec: 10},
+			want: map[string]string{"Hex": "0xff", "Octal": "10", "Binary": "0b101", "Base36": "z", "Dec": "10"},
+		},
+		{
+			name: "negative",
+			row:  baseRow{Octal: -8, Binary: -128, Dec: -1},
+			want: map[string]string{"Hex": "0x0", "Octal": "-10", "Binary": "-0b10000000", "Base36": "0", "Dec": "-1"},
+		},
+		{
+			name: "max",
+			row:  baseRow{Hex: math.MaxUint32, Base36: math.MaxUint64},
+			want: map[string]string{"Hex": "0xffffffff", "Octal": "0", "Binary": "0b0", "Base36": "3w5e11264sgsf", "Dec": "0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := TableRow(tt.row)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TableRow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	_, _, err := TableRow(badBaseRow{})
+	if want := "Invalid base '37' for field 'N' in badBaseRow"; err == nil || err.Error() != want {
+		t.Errorf("TableRow() error = %v, want %q", err, want)
+	}
+}
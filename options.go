@@ -0,0 +1,674 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"strings"
+	"time"
+)
+
+// Option modifies how a table is generated
+type Option func(*options)
+
+type options struct {
+	derived          []derivedColumn
+	columnWidths     []int
+	fixedWidths      map[string]int
+	maxColumnWidth   int
+	minColumnWidth   int
+	fullHeaders      bool
+	ellipsis         string
+	truncation       Truncate
+	overflow         bool
+	wrap             bool
+	multiLine        bool
+	totalWidth       int
+	terminalWidth    bool
+	columnsEnv       bool
+	verticalFallback bool
+	defaultWidth     int
+	shrinkFloor      int
+	stripe           int
+	columnFilter     func(string) bool
+	separator        string
+	headerRule       string
+	headerAlign      Align
+	aligns           map[string]Align
+	padding          *int
+	rowCount         bool
+	strictTypes      bool
+	strictRowTypes   bool
+	nilRows          bool
+	workers          int
+	notSupported     string
+	null             string
+	nilError         *string
+	jsonFallback     bool
+	bestEffort       bool
+	byteEncoding     string
+	mapPairSep       string
+	mapKeySep        string
+	listSep          string
+
+	omitGroupColumn  bool
+	allowMissing     bool
+	noHeaderFallback bool
+	tagFallback      bool
+	indexColumn      bool
+	indexHeader      string
+	headerCase       HeaderCase
+	headerOverrides  map[string]string
+	columnGroups     map[string]string
+	title            string
+	titleUnderline   string
+	escapeSeparator  bool
+	newlineMarker    string
+
+	uniqueHeaders          bool
+	caseInsensitiveHeaders bool
+	rawFieldHeaders        bool
+
+	now             func() time.Time
+	timeFormat      string
+	csvAbsoluteTime bool
+	csvHeader       bool
+	csvFooter       bool
+	csvTrailer      bool
+	csvChecksum     bool
+	collectErrors   bool
+
+	controlCharMode    ControlChars
+	csvControlCharMode ControlChars
+
+	csv   bool // generating CSV output
+	table bool // generating a plain text table
+}
+
+// DerivedFunc computes the value of a derived column for a single row
+type DerivedFunc func(TableStruct) string
+
+type derivedColumn struct {
+	name   string
+	header string
+	fn     DerivedFunc
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		notSupported: NOT_SUPPORTED,
+		now:          time.Now,
+		mapPairSep:   ",",
+		mapKeySep:    "=",
+		listSep:      ",",
+		separator:    " | ",
+		headerRule:   "=",
+		ellipsis:     "…",
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithDerivedColumn adds a column which is not a struct field.  The name
+// is used in the list of fields like any other field name and the value
+// is computed by calling fn for each row.
+func WithDerivedColumn(name, header string, fn DerivedFunc) Option {
+	return func(o *options) {
+		o.derived = append(o.derived, derivedColumn{
+			name:   name,
+			header: header,
+			fn:     fn,
+		})
+	}
+}
+
+// WithColumnFilter removes the columns whose struct field name fn returns
+// false for.  Useful to hide columns based on a verbosity flag without
+// changing the list of fields.
+func WithColumnFilter(fn func(fieldName string) bool) Option {
+	return func(o *options) {
+		o.columnFilter = fn
+	}
+}
+
+// WithTotalWidth limits the total width of the table.  The widest columns
+// are shrunk proportionally and their values truncated to fit.  Columns
+// with a lower priority tag option, `header:"Notes,priority=-1"`, are
+// shrunk first and dropped if the table still does not fit.
+func WithTotalWidth(width int) Option {
+	return func(o *options) {
+		o.totalWidth = width
+	}
+}
+
+// WithTerminalWidth fits tables written to a terminal in its width like
+// WithTotalWidth.  When the output is not a terminal defaultWidth is used,
+// where 0 leaves the columns at their natural width, unless WithColumnsEnv
+// is set.  WithTotalWidth takes precedence.
+func WithTerminalWidth(defaultWidth int) Option {
+	return func(o *options) {
+		o.terminalWidth = true
+		o.defaultWidth = defaultWidth
+	}
+}
+
+// WithTerminalFit fits tables in the width of the terminal they are
+// written to, leaving them at their natural width otherwise.  Same as
+// WithTerminalWidth(0).
+func WithTerminalFit() Option {
+	return WithTerminalWidth(0)
+}
+
+// WithColumnsEnv makes WithTerminalWidth & WithTerminalFit use $COLUMNS,
+// when set, before the default width if the output is not a terminal
+func WithColumnsEnv() Option {
+	return func(o *options) {
+		o.columnsEnv = true
+	}
+}
+
+// WithVerticalFallback renders the rows like GenerateVertical instead of
+// shrinking the columns when the table does not fit in WithTotalWidth or
+// the terminal width.  The title, aggregates & row count are kept.
+func WithVerticalFallback() Option {
+	return func(o *options) {
+		o.verticalFallback = true
+	}
+}
+
+// WithShrinkFloor sets the minimum width a column may be shrunk to when
+// fitting the table in WithTotalWidth.  Defaults to the header width.
+func WithShrinkFloor(width int) Option {
+	return func(o *options) {
+		o.shrinkFloor = width
+	}
+}
+
+// WithStripe inserts a separator line after every n rows to make wide
+// tables easier to read.  Zero (the default) never inserts a separator.
+func WithStripe(n int) Option {
+	return func(o *options) {
+		o.stripe = n
+	}
+}
+
+// WithRowCount prints the number of rows, such as "(42 rows)", after the
+// table.  Not included in CSV output.
+func WithRowCount() Option {
+	return func(o *options) {
+		o.rowCount = true
+	}
+}
+
+// WithStrictTypes returns an error for any field which can not be converted
+// instead of rendering it as NOT_SUPPORTED
+func WithStrictTypes() Option {
+	return func(o *options) {
+		o.strictTypes = true
+	}
+}
+
+// WithOmitGroupColumn removes the groupBy field from the body of the
+// table generated by GenerateTableGrouped
+func WithOmitGroupColumn() Option {
+	return func(o *options) {
+		o.omitGroupColumn = true
+	}
+}
+
+// WithNotSupported sets the placeholder rendered for fields of unsupported
+// types.  Defaults to NOT_SUPPORTED.
+func WithNotSupported(placeholder string) Option {
+	return func(o *options) {
+		o.notSupported = placeholder
+	}
+}
+
+// WithJSONFallback renders struct, slice, map & array fields which are
+// otherwise not supported as single line JSON.  Individual fields can opt
+// in via the json tag option: `header:"Range,json"`
+func WithJSONFallback() Option {
+	return func(o *options) {
+		o.jsonFallback = true
+	}
+}
+
+// WithBestEffort renders fields of unsupported types via fmt's %v verb
+// instead of the WithNotSupported placeholder.  Takes precedence over
+// WithStrictTypes.
+func WithBestEffort(enabled bool) Option {
+	return func(o *options) {
+		o.bestEffort = enabled
+	}
+}
+
+// WithColumnWidths uses the given widths instead of calculating the width
+// of each column from its contents.  Useful to align multiple tables via
+// ColumnWidths.  Values longer than their column are truncated unless
+// WithOverflow is set.
+func WithColumnWidths(widths []int) Option {
+	return func(o *options) {
+		o.columnWidths = widths
+	}
+}
+
+// WithFixedWidths pins the width of the columns of the given fields, like
+// the width tag option: `header:"Host,width=20"`.  Shorter values are
+// padded and longer ones truncated with the ellipsis regardless of the
+// content of the other rows.  Does not apply to CSV.
+func WithFixedWidths(widths map[string]int) Option {
+	return func(o *options) {
+		o.fixedWidths = widths
+	}
+}
+
+// WithFullHeaders never truncates headers: columns are kept at least as
+// wide as their header even if that exceeds their max width or shrink
+// floor.  By default headers are truncated like the values.
+func WithFullHeaders() Option {
+	return func(o *options) {
+		o.fullHeaders = true
+	}
+}
+
+// WithMinColumnWidth pads every column without a minwidth tag option,
+// `header:"OK,minwidth=4"`, to at least width characters so narrow columns
+// are not cramped.  Does not apply to CSV.
+func WithMinColumnWidth(width int) Option {
+	return func(o *options) {
+		o.minColumnWidth = width
+	}
+}
+
+// WithMaxColumnWidth limits the width of every column without a maxwidth
+// tag option: `header:"URL,maxwidth=40"`.  Longer values & headers are
+// truncated with an ellipsis.  Does not apply to CSV.
+func WithMaxColumnWidth(width int) Option {
+	return func(o *options) {
+		o.maxColumnWidth = width
+	}
+}
+
+// WithEllipsis sets the suffix of values truncated to their max width.
+// Defaults to "…", use "..." for ASCII only output.
+func WithEllipsis(ellipsis string) Option {
+	return func(o *options) {
+		o.ellipsis = ellipsis
+	}
+}
+
+// WithTruncation sets which part of values truncated to their max width is
+// replaced by the ellipsis.  Defaults to TruncateEnd.  Can be set per field
+// via the trunc tag option: `header:"Path,maxwidth=30,trunc=start"`
+func WithTruncation(mode Truncate) Option {
+	return func(o *options) {
+		o.truncation = mode
+	}
+}
+
+// WithOverflow prints values which are wider than their column in full
+// rather than truncating them
+func WithOverflow() Option {
+	return func(o *options) {
+		o.overflow = true
+	}
+}
+
+// WithNullPlaceholder sets the value rendered for nil pointers, nil
+// interfaces and invalid database/sql Null values.  Defaults to "".
+func WithNullPlaceholder(placeholder string) Option {
+	return func(o *options) {
+		o.null = placeholder
+	}
+}
+
+// WithByteEncoding sets how []byte fields are rendered: "string" (the
+// default), "hex" or "base64".  Individual fields can override this via
+// the enc tag option: `header:"Hash,enc=hex"`
+func WithByteEncoding(enc string) Option {
+	return func(o *options) {
+		o.byteEncoding = enc
+	}
+}
+
+// WithTimeFormat sets the layout time.Time fields are rendered with in
+// every output format.  Defaults to time.RFC3339.  Individual fields can
+// override this via the timefmt tag option, which also accepts the names
+// rfc3339, rfc1123, kitchen, date & datetime: `header:"Day,timefmt=date"`
+func WithTimeFormat(layout string) Option {
+	return func(o *options) {
+		o.timeFormat = layout
+	}
+}
+
+// WithClock sets the function used to get the current time for fields
+// with the reltime tag option.  Defaults to time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+// WithCSVAbsoluteTime renders fields with the reltime tag option in
+// RFC3339 when generating CSV
+func WithCSVAbsoluteTime() Option {
+	return func(o *options) {
+		o.csvAbsoluteTime = true
+	}
+}
+
+// WithCSVHeader writes the headers as the first record of CSV output so
+// it can be read back by ParseCSV
+func WithCSVHeader() Option {
+	return func(o *options) {
+		o.csvHeader = true
+	}
+}
+
+// WithCSVFooter appends the aggregates of columns with the agg tag option
+// as the last row of CSV output
+func WithCSVFooter() Option {
+	return func(o *options) {
+		o.csvFooter = true
+	}
+}
+
+// WithCSVTrailer appends a record with the number of rows, such as
+// "#rows=42", to CSV output so truncated output can be detected.  With
+// checksum the record also has the SHA-256 of every preceding record:
+// "#rows=42","sha256=<hex>"
+func WithCSVTrailer(checksum bool) Option {
+	return func(o *options) {
+		o.csvTrailer = true
+		o.csvChecksum = checksum
+	}
+}
+
+// WithMapSeparators sets the separator between the key=value pairs of map
+// fields and between each key & value.  Defaults to "," and "=".  The pair
+// separator can be set per field via the sep tag option.
+func WithMapSeparators(pairSep, keySep string) Option {
+	return func(o *options) {
+		o.mapPairSep = pairSep
+		o.mapKeySep = keySep
+	}
+}
+
+// WithCollectErrors makes RenderMulti render every output even if one of
+// them fails and return the errors of all the failed outputs together
+func WithCollectErrors() Option {
+	return func(o *options) {
+		o.collectErrors = true
+	}
+}
+
+// WithNilError sets the value rendered for error fields which are nil,
+// such as "ok".  Defaults to the WithNullPlaceholder value.
+func WithNilError(placeholder string) Option {
+	return func(o *options) {
+		o.nilError = &placeholder
+	}
+}
+
+// WithAllowMissingFields renders fields which are not in the struct as an
+// empty column instead of returning an error
+func WithAllowMissingFields() Option {
+	return func(o *options) {
+		o.allowMissing = true
+	}
+}
+
+// WithEscapeSeparator escapes any | in the headers & values of the table
+// as \| so they can not be confused with the column separator
+func WithEscapeSeparator() Option {
+	return func(o *options) {
+		o.escapeSeparator = true
+	}
+}
+
+// WithWrap wraps the values of every column on word boundaries instead of
+// truncating them.  Single columns can be wrapped with the wrap tag option,
+// `header:"Description,wrap"`, or `wrap=40` to also limit their width.
+func WithWrap() Option {
+	return func(o *options) {
+		o.wrap = true
+	}
+}
+
+// WithMultiLineCells renders values with newlines over multiple lines
+// instead of escaping the newlines as \n.  The other columns of the row
+// are left blank on the extra lines.  Does not apply to CSV.
+func WithMultiLineCells() Option {
+	return func(o *options) {
+		o.multiLine = true
+	}
+}
+
+// WithNewlineMarker replaces newlines in the headers & values of the table
+// with a visible marker, such as "␤", so each row stays on a single line
+func WithNewlineMarker(marker string) Option {
+	return func(o *options) {
+		o.newlineMarker = marker
+	}
+}
+
+// WithListSeparator sets the separator between the elements of slice and
+// array fields.  Defaults to ",".  Can be set per field via the sep tag
+// option.
+func WithListSeparator(sep string) Option {
+	return func(o *options) {
+		o.listSep = sep
+	}
+}
+
+// WithControlChars sets how newlines, tabs and other C0 & C1 control
+// characters in headers & values are handled.  By default they are escaped
+// in tables so each row is a single line and can not mess with the
+// terminal, and left as is in every other output.
+func WithControlChars(mode ControlChars) Option {
+	return func(o *options) {
+		o.controlCharMode = mode
+	}
+}
+
+// WithCSVControlChars is WithControlChars for CSV output only.  CSV keeps
+// control characters as is by default.
+func WithCSVControlChars(mode ControlChars) Option {
+	return func(o *options) {
+		o.csvControlCharMode = mode
+	}
+}
+
+// WithNoHeaderFallback leaves the header of fields without a header tag
+// empty instead of deriving it from the field name via HeaderFromFieldName
+func WithNoHeaderFallback() Option {
+	return func(o *options) {
+		o.noHeaderFallback = true
+	}
+}
+
+// WithRawFieldHeaders uses the struct field names as the headers instead
+// of calling GetHeader, which is handy to debug an unfamiliar struct
+func WithRawFieldHeaders(raw bool) Option {
+	return func(o *options) {
+		o.rawFieldHeaders = raw
+	}
+}
+
+// WithTagFallback uses the name in the json tag, then the yaml tag, as
+// the header of fields with no header tag.  Fields tagged json:"-" are
+// excluded.
+func WithTagFallback() Option {
+	return func(o *options) {
+		o.tagFallback = true
+	}
+}
+
+// WithIndexColumn prepends a column with the 1-based row number using the
+// given header, such as "#"
+func WithIndexColumn(header string) Option {
+	return func(o *options) {
+		o.indexColumn = true
+		o.indexHeader = header
+	}
+}
+
+// WithStrictRowTypes returns an error if the rows are not all the same
+// struct type
+func WithStrictRowTypes() Option {
+	return func(o *options) {
+		o.strictRowTypes = true
+	}
+}
+
+// WithWorkers converts the rows to strings using n goroutines, which speeds
+// up large tables.  The order of the rows is unchanged.  Any DerivedFunc
+// and TypeFormatter must be safe to call concurrently.
+func WithWorkers(n int) Option {
+	return func(o *options) {
+		o.workers = n
+	}
+}
+
+// WithNilRows renders nil rows with the WithNullPlaceholder value in every
+// column instead of skipping them.  Either way, a typed nil row such as
+// (*Host)(nil) defines the headers of a table without any other rows.
+func WithNilRows() Option {
+	return func(o *options) {
+		o.nilRows = true
+	}
+}
+
+// WithHeaderCase transforms the case of the headers.  Does not apply to
+// CSV output.
+func WithHeaderCase(c HeaderCase) Option {
+	return func(o *options) {
+		o.headerCase = c
+	}
+}
+
+// WithHeaderOverrides replaces the header of the given struct field names,
+// such as {"Hostname": "Host"}, without changing the header tags.  Returns
+// an error for any field which does not exist.
+func WithHeaderOverrides(headers map[string]string) Option {
+	return func(o *options) {
+		o.headerOverrides = headers
+	}
+}
+
+// WithUniqueHeaders returns an error if two of the selected columns have
+// the same header
+func WithUniqueHeaders() Option {
+	return func(o *options) {
+		o.uniqueHeaders = true
+	}
+}
+
+// WithCaseInsensitiveHeaders treats headers which only differ by case as
+// duplicates in CheckHeaders and WithUniqueHeaders
+func WithCaseInsensitiveHeaders() Option {
+	return func(o *options) {
+		o.caseInsensitiveHeaders = true
+	}
+}
+
+// WithColumnGroups maps field names to a group name which is printed
+// centered above the headers of adjacent columns in the same group.  The
+// group can also be set via the group tag option: `header:"Bytes,group=RX"`
+func WithColumnGroups(groups map[string]string) Option {
+	return func(o *options) {
+		o.columnGroups = groups
+	}
+}
+
+// WithTitle prints title centered over GenerateTable output.  Each line of
+// a multi-line title is centered on its own.  Empty means no title.
+func WithTitle(title string) Option {
+	return func(o *options) {
+		o.title = title
+	}
+}
+
+// WithTitleUnderline underlines each line of the WithTitle title with the
+// given character, such as "-".  A longer string is repeated and cut to the
+// width of the line.
+func WithTitleUnderline(char string) Option {
+	return func(o *options) {
+		o.titleUnderline = char
+	}
+}
+
+// WithColumnSeparator sets the string printed between the columns of a
+// table, such as "  " or " │ ".  Defaults to " | ".
+func WithColumnSeparator(sep string) Option {
+	return func(o *options) {
+		o.separator = sep
+	}
+}
+
+// WithAlignment sets the alignment of the columns of the given struct field
+// names, overriding the align tag option: `header:"Size,align=right"`.
+// Columns default to AlignAuto.
+func WithAlignment(aligns map[string]Align) Option {
+	return func(o *options) {
+		o.aligns = aligns
+	}
+}
+
+// WithLeftAlignedHeaders left aligns every header instead of following the
+// alignment of its column
+func WithLeftAlignedHeaders() Option {
+	return WithHeaderAlignment(AlignLeft)
+}
+
+// WithHeaderAlignment aligns every header the same way instead of following
+// the alignment of its column, such as AlignCenter
+func WithHeaderAlignment(align Align) Option {
+	return func(o *options) {
+		o.headerAlign = align
+	}
+}
+
+// WithPadding sets the number of spaces on each side of the column
+// separator, which defaults to 1.  Zero gives a compact "a|b" layout.  The
+// padding counts toward WithTotalWidth.
+func WithPadding(n int) Option {
+	return func(o *options) {
+		o.padding = &n
+	}
+}
+
+// columnSeparator returns the separator between columns including any
+// WithPadding spaces
+func (o *options) columnSeparator() string {
+	if o.padding == nil {
+		return o.separator
+	}
+	pad := strings.Repeat(" ", *o.padding)
+	return pad + strings.TrimSpace(o.separator) + pad
+}
+
+// WithHeaderRule sets the character repeated under the header of a table,
+// such as "-" or "─".  Defaults to "=" and "" disables the rule.  A longer
+// string, such as "=-", is repeated and cut to the width of the table.
+func WithHeaderRule(char string) Option {
+	return func(o *options) {
+		o.headerRule = char
+	}
+}
@@ -0,0 +1,87 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Generates an Emacs Org-mode table: |-delimited rows with a |---+---|
+// rule under the headers
+func GenerateOrg(w io.Writer, tables []TableStruct, fields []string, opts ...Option) error {
+	o := newOptions(opts)
+	o.table = true
+	t, err := buildTable(tables, fields, o)
+	if err != nil {
+		return err
+	}
+	return generateOrg(w, t)
+}
+
+func generateOrg(w io.Writer, t *tableData) error {
+	headers := make([]string, len(t.headers))
+	colWidth := make([]int, len(t.headers))
+	for i, header := range t.headers {
+		headers[i] = orgEscape(header)
+		colWidth[i] = displayWidth(headers[i])
+	}
+	rows := make([][]string, len(t.rows))
+	for i, row := range t.rows {
+		rows[i] = make([]string, len(row))
+		for j, value := range row {
+			rows[i][j] = orgEscape(value)
+			if width := displayWidth(rows[i][j]); width > colWidth[j] {
+				colWidth[j] = width
+			}
+		}
+	}
+
+	if err := orgRow(w, t, headers, colWidth); err != nil {
+		return err
+	}
+	rule := make([]string, len(colWidth))
+	for i, width := range colWidth {
+		rule[i] = strings.Repeat("-", width+2)
+	}
+	if _, err := fmt.Fprintf(w, "|%s|\n", strings.Join(rule, "+")); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := orgRow(w, t, row, colWidth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// orgRow prints a row of values padded to the width of each column
+func orgRow(w io.Writer, t *tableData, values []string, colWidth []int) error {
+	cells := make([]string, len(values))
+	for i, value := range values {
+		cells[i] = pad(value, colWidth[i], alignAt(t.aligns, i), " ")
+	}
+	_, err := fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+	return err
+}
+
+// orgEscape escapes the cell separator with the Org entity for |
+func orgEscape(value string) string {
+	return strings.ReplaceAll(value, "|", `\vert{}`)
+}
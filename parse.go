@@ -0,0 +1,219 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseCSV reads CSV with a header line, such as GenerateCSV writes with
+// WithCSVHeader, into out, which must be a pointer to a slice of structs or
+// struct pointers.  Each column is matched to the
+// field with that header, or that name, ignoring case.  Times are parsed
+// in the layout they are rendered in: the timefmt tag option, WithTimeFormat
+// or RFC3339.  Fields of a type which can't be parsed are an error, even if
+// there are no rows.
+func ParseCSV(r io.Reader, out interface{}, opts ...Option) error {
+	o := newOptions(opts)
+	slice := reflect.ValueOf(out)
+	if slice.Kind() != reflect.Ptr || slice.IsNil() || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("Invalid type %v is not a pointer to a slice", reflect.TypeOf(out))
+	}
+	slice = slice.Elem()
+	elemType := slice.Type().Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("Invalid type %v is not a slice of structs", slice.Type())
+	}
+
+	infos, err := csvFieldInfos(structType, o)
+	if err != nil {
+		return err
+	}
+
+	reader := csv.NewReader(r)
+	columns, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	fields, err := csvFields(columns, structType, opts)
+	if err != nil {
+		return err
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		elem := reflect.New(structType).Elem()
+		for i, value := range record {
+			layout := timeLayout(infos[fields[i]], o)
+			if err := parseValue(elem.FieldByName(fields[i]), value, layout); err != nil {
+				return fmt.Errorf("Unable to parse column '%s' into field '%s' in %s: %s",
+					columns[i], fields[i], structType.Name(), err.Error())
+			}
+		}
+		if elemType.Kind() == reflect.Ptr {
+			elem = elem.Addr()
+		}
+		slice.Set(reflect.Append(slice, elem))
+	}
+}
+
+// csvFieldInfos returns the fieldInfo of each field of the struct type t
+// which can be selected, or an error if one can't be parsed
+func csvFieldInfos(t reflect.Type, o *options) (map[string]*fieldInfo, error) {
+	fields, err := orderedFields(t, o)
+	if err != nil {
+		return nil, err
+	}
+	infos := make(map[string]*fieldInfo, len(fields))
+	for _, f := range fields {
+		if !parseable(f.Type) {
+			return infos, fmt.Errorf("Unsupported field '%s' of type %v in %s", f.Name, f.Type, t.Name())
+		}
+		if infos[f.Name], err = newFieldInfo(t, f); err != nil {
+			return infos, err
+		}
+	}
+	return infos, nil
+}
+
+// parseable returns true if parseValue can set a value of type t
+func parseable(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType || t == durationType {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// csvFields returns the struct field name for each CSV column
+func csvFields(columns []string, t reflect.Type, opts []Option) ([]string, error) {
+	headers, err := GetHeaders(reflect.New(t).Interface(), opts...)
+	if err != nil {
+		return []string{}, err
+	}
+	fields := make([]string, len(columns))
+	for i, column := range columns {
+		column = strings.TrimSpace(column)
+		for _, fh := range headers {
+			if strings.EqualFold(fh.Header, column) {
+				fields[i] = fh.Field
+				break
+			}
+		}
+		if fields[i] != "" {
+			continue
+		}
+		for _, fh := range headers {
+			if strings.EqualFold(fh.Field, column) {
+				fields[i] = fh.Field
+				break
+			}
+		}
+		if fields[i] == "" {
+			return fields, fmt.Errorf("Invalid CSV column '%s' for %s", column, t.Name())
+		}
+	}
+	return fields, nil
+}
+
+// parseValue sets v to value, parsing times in layout.  An empty value
+// leaves v as the zero value.
+func parseValue(v reflect.Value, value string, layout string) error {
+	if value == "" {
+		return nil
+	}
+	if v.Kind() == reflect.Ptr {
+		v.Set(reflect.New(v.Type().Elem()))
+		v = v.Elem()
+	}
+
+	switch {
+	case v.Type() == timeType:
+		t, err := time.Parse(layout, value)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(value, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported kind %s", v.Kind())
+	}
+	return nil
+}
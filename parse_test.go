@@ -0,0 +1,150 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type csvRow struct {
+	Name    string        `header:"Name"`
+	Count   int           `header:"Count"`
+	Ratio   float64       `header:"Ratio"`
+	Enabled bool          `header:"Enabled"`
+	When    time.Time     `header:"When"`
+	Took    time.Duration `header:"Took"`
+	Note    *string       `header:"Note"`
+}
+
+func (r csvRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestParseCSVRoundTrip(t *testing.T) {
+	note := "with, comma"
+	rows := []csvRow{
+		{Name: "a", Count: 1, Ratio: 0.5, Enabled: true, When: time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC), Took: 90 * time.Second, Note: &note},
+		{Name: "b \"quoted\"", Count: -2, Ratio: 10, When: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}
+	tables := []TableStruct{}
+	for _, row := range rows {
+		tables = append(tables, row)
+	}
+
+	var buf bytes.Buffer
+	err := RenderMulti(tables, nil, []Output{{Format: FormatCSV, Writer: &buf}}, WithCSVHeader())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := []csvRow{}
+	if err := ParseCSV(&buf, &got); err != nil {
+		t.Fatalf("ParseCSV(%q): %s", buf.String(), err)
+	}
+	if !reflect.DeepEqual(got, rows) {
+		t.Errorf("ParseCSV() = %+v, want %+v", got, rows)
+	}
+}
+
+func TestParseCSVInvalidColumn(t *testing.T) {
+	got := []csvRow{}
+	if err := ParseCSV(bytes.NewBufferString("Nope\nx\n"), &got); err == nil {
+		t.Error("expected an error for an unknown column")
+	}
+}
+
+type csvTimeRow struct {
+	Name string    `header:"Name"`
+	Day  time.Time `header:"Day,timefmt=date"`
+	At   time.Time `header:"At"`
+}
+
+func (r csvTimeRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+type csvMapRow struct {
+	Name string            `header:"Name"`
+	Tags map[string]string `header:"Tags"`
+}
+
+func (r csvMapRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestParseCSVTimeFormat(t *testing.T) {
+	row := csvTimeRow{
+		Name: "a",
+		Day:  time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC),
+		At:   time.Date(2021, 3, 4, 5, 6, 0, 0, time.UTC),
+	}
+	tests := []struct {
+		name string
+		opts []Option
+		csv  string
+	}{
+		{"default", nil, "Name,Day,At\na,2021-03-04,2021-03-04T05:06:00Z\n"},
+		{"WithTimeFormat", []Option{WithTimeFormat("2006/01/02 15:04")}, "Name,Day,At\na,2021-03-04,2021/03/04 05:06\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			opts := append([]Option{WithCSVHeader()}, tt.opts...)
+			err := RenderMulti([]TableStruct{row}, nil, []Output{{Format: FormatCSV, Writer: &buf}}, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.csv {
+				t.Errorf("CSV = %q, want %q", buf.String(), tt.csv)
+			}
+
+			got := []csvTimeRow{}
+			if err := ParseCSV(&buf, &got, tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, []csvTimeRow{row}) {
+				t.Errorf("ParseCSV() = %+v, want %+v", got, row)
+			}
+		})
+	}
+}
+
+func TestParseCSVUnsupported(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+	}{
+		{"empty", ""},
+		{"header only", "Name\n"},
+		{"unused field", "Name\na\n"},
+		{"used field", "Name,Tags\na,b\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []csvMapRow{}
+			err := ParseCSV(bytes.NewBufferString(tt.csv), &got)
+			want := "Unsupported field 'Tags' of type map[string]string in csvMapRow"
+			if err == nil || err.Error() != want {
+				t.Errorf("ParseCSV() error = %v, want %q", err, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"sync"
+)
+
+// tableRows converts each non-nil row via tableRow using up to WithWorkers
+// goroutines.  Returns the values & headers of each row in the same order
+// as tables, nil for nil rows, and the error of the first row which failed.
+func tableRows(tables []TableStruct, fields []string, o *options) ([]map[string]string, []map[string]string, error) {
+	rows := make([]map[string]string, len(tables))
+	headers := make([]map[string]string, len(tables))
+	errs := make([]error, len(tables))
+
+	convert := func(i int) {
+		if tables[i] != nil {
+			rows[i], headers[i], errs[i] = tableRow(tables[i], fields, o)
+		}
+	}
+
+	if o.workers <= 1 || len(tables) < 2 {
+		for i := range tables {
+			convert(i)
+			if errs[i] != nil {
+				return rows, headers, errs[i]
+			}
+		}
+		return rows, headers, nil
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < o.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				convert(i)
+			}
+		}()
+	}
+	for i := range tables {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return rows, headers, err
+		}
+	}
+	return rows, headers, nil
+}
@@ -0,0 +1,97 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type benchRow struct {
+	Name    string        `header:"Name"`
+	Count   int           `header:"Count"`
+	Ratio   float64       `header:"Ratio"`
+	Enabled bool          `header:"Enabled"`
+	Took    time.Duration `header:"Took,unit=ms"`
+	Tags    []string      `header:"Tags"`
+}
+
+func (r benchRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func benchRows(n int) []TableStruct {
+	rows := make([]TableStruct, n)
+	for i := range rows {
+		rows[i] = benchRow{
+			Name:    fmt.Sprintf("row-%d", i),
+			Count:   i,
+			Ratio:   float64(i) / 7,
+			Enabled: i%2 == 0,
+			Took:    time.Duration(i) * time.Millisecond,
+			Tags:    []string{"a", "b"},
+		}
+	}
+	return rows
+}
+
+func TestTableRowsWorkers(t *testing.T) {
+	rows := benchRows(1000)
+	rows[10] = nil
+	want, err := GenerateTableString(rows, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, workers := range []int{2, 8, 2000} {
+		got, err := GenerateTableString(rows, nil, WithWorkers(workers))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("WithWorkers(%d) output differs from sequential", workers)
+		}
+	}
+}
+
+func TestTableRowsWorkersError(t *testing.T) {
+	rows := []TableStruct{
+		unsupportedRow{Name: "a"},
+		colorRow{Name: "b"},
+		unsupportedRow{Name: "c"},
+	}
+	_, _, err := tableRows(rows, nil, newOptions([]Option{WithStrictTypes(), WithWorkers(4)}))
+	if want := "Unable to format field 'Fn' in unsupportedRow: unsupported kind func"; err == nil || err.Error() != want {
+		t.Errorf("tableRows() error = %v, want %q", err, want)
+	}
+}
+
+func BenchmarkTableRows(b *testing.B) {
+	rows := benchRows(10000)
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			o := newOptions([]Option{WithWorkers(workers)})
+			for i := 0; i < b.N; i++ {
+				if _, _, err := tableRows(rows, nil, o); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+var (
+	tagKeyLock sync.RWMutex
+	tagKey     = TABLE_HEADER_TAG
+)
+
+// SetHeaderTagKey changes the struct tag key used for headers & their
+// options from TABLE_HEADER_TAG ("header") to key, such as "table"
+func SetHeaderTagKey(key string) {
+	tagKeyLock.Lock()
+	defer tagKeyLock.Unlock()
+	tagKey = key
+}
+
+// HeaderTagKey returns the struct tag key used for headers
+func HeaderTagKey() string {
+	tagKeyLock.RLock()
+	defer tagKeyLock.RUnlock()
+	return tagKey
+}
+
+// tags checked in order for the header name by WithTagFallback
+var fallbackTags = []string{"json", "yaml"}
+
+// tagOptions are the options which follow the header name in the tag:
+// `header:"Name,opt=value,flag"`.  Flags have an empty value.
+type tagOptions map[string]string
+
+// has returns true if the option is set
+func (t tagOptions) has(key string) bool {
+	_, ok := t[key]
+	return ok
+}
+
+// ParseHeaderTag splits a header tag into the header name and the options
+// which follow it: `header:"Name,align=right,omitempty"`.  Flags such as
+// omitempty have an empty value.  Returns an error for an option with an
+// empty key or an option which is set more than once.
+func ParseHeaderTag(tag string) (string, map[string]string, error) {
+	opts := map[string]string{}
+	parts := strings.Split(tag, ",")
+	for _, part := range parts[1:] {
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if kv[0] == "" {
+			return parts[0], opts, fmt.Errorf("empty option name in '%s'", part)
+		}
+		if _, ok := opts[kv[0]]; ok {
+			return parts[0], opts, fmt.Errorf("duplicate option '%s'", kv[0])
+		}
+		if len(kv) == 2 {
+			opts[kv[0]] = kv[1]
+		} else {
+			opts[kv[0]] = ""
+		}
+	}
+	return parts[0], opts, nil
+}
+
+// parseFieldTag parses the header tag of the field in struct type t
+func parseFieldTag(t reflect.Type, f reflect.StructField) (string, tagOptions, error) {
+	name, opts, err := ParseHeaderTag(f.Tag.Get(HeaderTagKey()))
+	if err != nil {
+		return name, opts, fmt.Errorf("Invalid header tag for field '%s' in %s: %s", f.Name, t.Name(), err.Error())
+	}
+	return name, opts, nil
+}
+
+// isExcluded returns true for fields tagged `header:"-"` which are never
+// rendered.  Like encoding/json, use `header:"-,"` for a header named "-".
+func isExcluded(f reflect.StructField) bool {
+	return f.Tag.Get(HeaderTagKey()) == "-"
+}
+
+// isExcluded returns true if the field is excluded via the header tag or
+// via the json/yaml tags when WithTagFallback is set
+func (o *options) isExcluded(f reflect.StructField) bool {
+	if isExcluded(f) {
+		return true
+	}
+	if !o.tagFallback || f.Tag.Get(HeaderTagKey()) != "" {
+		return false
+	}
+	for _, key := range fallbackTags {
+		if tag, ok := f.Tag.Lookup(key); ok {
+			return tag == "-"
+		}
+	}
+	return false
+}
+
+// fallbackHeader returns the header for a field with no header tag: the
+// name in the json or yaml tag when WithTagFallback is set, otherwise
+// derived from the field name
+func (o *options) fallbackHeader(f reflect.StructField) string {
+	if o.tagFallback {
+		for _, key := range fallbackTags {
+			name := strings.Split(f.Tag.Get(key), ",")[0]
+			if name != "" && name != "-" {
+				return name
+			}
+		}
+	}
+	if o.noHeaderFallback {
+		return ""
+	}
+	return HeaderFromFieldName(f.Name)
+}
+
+// HeaderFromFieldName converts a Go field name into a header by splitting
+// the CamelCase words while keeping initialisms together:
+// "UserID" => "User ID", "HTTPServerURL" => "HTTP Server URL", "CPUs" => "CPUs"
+func HeaderFromFieldName(name string) string {
+	runes := []rune(name)
+	words := []string{}
+	word := []rune{}
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = []rune{}
+		}
+	}
+
+	for i, r := range runes {
+		if r == '_' {
+			flush()
+			continue
+		}
+		if i > 0 && unicode.IsUpper(r) && len(word) > 0 {
+			prev := runes[i-1]
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) {
+				flush()
+			} else if unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) && !isPluralS(runes, i+1) {
+				// last upper case letter of an initialism starts the next word
+				flush()
+			}
+		}
+		word = append(word, r)
+	}
+	flush()
+	return strings.Join(words, " ")
+}
+
+// isPluralS returns true if runes[i] is an 's' which ends a word, such as
+// the plural of an initialism: CPUs
+func isPluralS(runes []rune, i int) bool {
+	if runes[i] != 's' {
+		return false
+	}
+	return i+1 == len(runes) || unicode.IsUpper(runes[i+1]) || runes[i+1] == '_'
+}
+
+// fieldTagOptions returns the header tag options of the field in the
+// struct type t.  Returns no options if the type is unknown or the field
+// does not exist, such as derived columns.
+func fieldTagOptions(t reflect.Type, fieldName string) tagOptions {
+	if t == nil {
+		return tagOptions{}
+	}
+	f, ok := t.FieldByName(fieldName)
+	if !ok {
+		return tagOptions{}
+	}
+	_, opts, err := ParseHeaderTag(f.Tag.Get(HeaderTagKey()))
+	if err != nil {
+		return tagOptions{}
+	}
+	return opts
+}
@@ -0,0 +1,295 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"reflect"
+	"testing"
+)
+
+type untaggedRow struct {
+	UserID string
+	IPAddr string
+}
+
+func (r untaggedRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestHeaderFromFieldName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Name", "Name"},
+		{"UserID", "User ID"},
+		{"IPAddr", "IP Addr"},
+		{"HTTPServerURL", "HTTP Server URL"},
+		{"CPUs", "CPUs"},
+		{"CPUsUsed", "CPUs Used"},
+		{"Id", "Id"},
+		{"ID", "ID"},
+		{"Snake_Case", "Snake Case"},
+		{"Port8080", "Port8080"},
+		{"A", "A"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := HeaderFromFieldName(tt.name); got != tt.want {
+			t.Errorf("HeaderFromFieldName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHeaderFallback(t *testing.T) {
+	row := untaggedRow{UserID: "u", IPAddr: "127.0.0.1"}
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{"fallback", nil, "User ID | IP Addr  \n===================\nu       | 127.0.0.1\n"},
+		{"no fallback", []Option{WithNoHeaderFallback()}, "  |          \n=============\nu | 127.0.0.1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateTableString([]TableStruct{row}, nil, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("GenerateTableString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type apiRow struct {
+	Header   string `header:"Header" json:"header_json"`
+	UserName string `json:"user_name,omitempty" yaml:"user_yaml"`
+	Region   string `yaml:"region"`
+	Empty    string `json:",omitempty" yaml:"empty_yaml"`
+	Internal string `json:"-"`
+	NoTags   string
+}
+
+func (r apiRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestTagFallback(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want []FieldHeader
+	}{
+		{
+			name: "disabled",
+			want: []FieldHeader{
+				{"Header", "Header"},
+				{"UserName", "User Name"},
+				{"Region", "Region"},
+				{"Empty", "Empty"},
+				{"Internal", "Internal"},
+				{"NoTags", "No Tags"},
+			},
+		},
+		{
+			name: "enabled",
+			opts: []Option{WithTagFallback()},
+			want: []FieldHeader{
+				{"Header", "Header"},
+				{"UserName", "user_name"},
+				{"Region", "region"},
+				{"Empty", "empty_yaml"},
+				{"NoTags", "No Tags"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetHeaders(apiRow{}, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetHeaders() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTagFallbackExcluded(t *testing.T) {
+	_, err := GenerateTableString([]TableStruct{apiRow{}}, []string{"Internal"}, WithTagFallback())
+	if want := "Unable to select excluded field 'Internal' in apiRow"; err == nil || err.Error() != want {
+		t.Errorf("GenerateTableString() error = %v, want %q", err, want)
+	}
+}
+
+func TestGetHeaderTagValues(t *testing.T) {
+	row := colorRow{}
+	ptr := &row
+	var iface interface{} = ptr
+	var table TableStruct = row
+	tests := []struct {
+		name string
+		v    reflect.Value
+	}{
+		{"value", reflect.ValueOf(row)},
+		{"pointer", reflect.ValueOf(ptr)},
+		{"double pointer", reflect.ValueOf(&ptr)},
+		{"interface", reflect.ValueOf(&iface).Elem()},
+		{"TableStruct", reflect.ValueOf(&table).Elem()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, err := GetHeaderTag(tt.v, "Value")
+			if err != nil || header != "Value" {
+				t.Errorf("GetHeaderTag(Value) = %q, %v, want %q", header, err, "Value")
+			}
+			_, err = GetHeaderTag(tt.v, "Missing")
+			if want := "Invalid field 'Missing' in colorRow"; err == nil || err.Error() != want {
+				t.Errorf("GetHeaderTag(Missing) error = %v, want %q", err, want)
+			}
+		})
+	}
+}
+
+func TestGetHeaderTagType(t *testing.T) {
+	for _, rowType := range []reflect.Type{reflect.TypeOf(colorRow{}), reflect.TypeOf(&colorRow{})} {
+		header, err := GetHeaderTagType(rowType, "Name")
+		if err != nil || header != "Name" {
+			t.Errorf("GetHeaderTagType(%s, Name) = %q, %v, want %q", rowType, header, err, "Name")
+		}
+	}
+	if _, err := GetHeaderTagType(reflect.TypeOf(3), "Name"); err == nil {
+		t.Errorf("GetHeaderTagType(int) want error")
+	}
+}
+
+func TestParseHeaderTag(t *testing.T) {
+	tests := []struct {
+		tag    string
+		header string
+		opts   map[string]string
+		err    string
+	}{
+		{"", "", map[string]string{}, ""},
+		{"Name", "Name", map[string]string{}, ""},
+		{"Name,align=right,omitempty", "Name", map[string]string{"align": "right", "omitempty": ""}, ""},
+		{",wrap", "", map[string]string{"wrap": ""}, ""},
+		{"Name,,sep==", "Name", map[string]string{"sep": "="}, ""},
+		{"Name,=x", "Name", map[string]string{}, "empty option name in '=x'"},
+		{"Name,wrap,wrap=10", "Name", map[string]string{"wrap": ""}, "duplicate option 'wrap'"},
+	}
+	for _, tt := range tests {
+		header, opts, err := ParseHeaderTag(tt.tag)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("ParseHeaderTag(%q) error = %v, want %q", tt.tag, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseHeaderTag(%q): %s", tt.tag, err)
+		}
+		if header != tt.header || !reflect.DeepEqual(opts, tt.opts) {
+			t.Errorf("ParseHeaderTag(%q) = %q, %v, want %q, %v", tt.tag, header, opts, tt.header, tt.opts)
+		}
+	}
+}
+
+type dupOptionRow struct {
+	Name string `header:"Name,wrap,wrap"`
+}
+
+func (r dupOptionRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestGetHeaderOptions(t *testing.T) {
+	tests := []struct {
+		name  string
+		v     interface{}
+		field string
+		want  map[string]string
+		err   string
+	}{
+		{"options", statusRow{}, "OK", map[string]string{"minwidth": "4"}, ""},
+		{"pointer", &statusRow{}, "Count", map[string]string{"minwidth": "3"}, ""},
+		{"no options", statusRow{}, "Name", map[string]string{}, ""},
+		{"invalid tag", dupOptionRow{}, "Name", nil, "Invalid header tag for field 'Name' in dupOptionRow: duplicate option 'wrap'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetHeaderOptions(reflect.ValueOf(tt.v), tt.field)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("GetHeaderOptions() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetHeaderOptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	_, _, err := TableRow(dupOptionRow{})
+	if err == nil {
+		t.Error("expected an error rendering an invalid header tag")
+	}
+}
+
+type tagKeyRow struct {
+	Name  string `header:"Name" table:"Host Name,align=right"`
+	Value string `header:"Value" table:"-"`
+}
+
+func (r tagKeyRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestSetHeaderTagKey(t *testing.T) {
+	rows := []TableStruct{tagKeyRow{Name: "a", Value: "b"}}
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{TABLE_HEADER_TAG, "Name | Value\n============\na    | b    \n"},
+		{"table", "Host Name\n=========\n        a\n"},
+		{TABLE_HEADER_TAG, "Name | Value\n============\na    | b    \n"},
+	}
+	t.Cleanup(func() { SetHeaderTagKey(TABLE_HEADER_TAG) })
+	for _, tt := range tests {
+		SetHeaderTagKey(tt.key)
+		if got := HeaderTagKey(); got != tt.key {
+			t.Errorf("HeaderTagKey() = %q, want %q", got, tt.key)
+		}
+		got, err := GenerateTableString(rows, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("GenerateTableString() with key %q = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+
+	// units of the unit tag option for time.Duration fields
+	durationUnits = map[string]time.Duration{
+		"ns": time.Nanosecond,
+		"us": time.Microsecond,
+		"µs": time.Microsecond,
+		"ms": time.Millisecond,
+		"s":  time.Second,
+		"m":  time.Minute,
+		"h":  time.Hour,
+	}
+
+	// named layouts accepted by the timefmt tag option
+	timeLayouts = map[string]string{
+		"rfc3339":  time.RFC3339,
+		"rfc1123":  time.RFC1123,
+		"kitchen":  time.Kitchen,
+		"date":     "2006-01-02",
+		"datetime": "2006-01-02 15:04:05",
+	}
+)
+
+// formatTime renders a time.Time in the layout of the timefmt tag option or
+// WithTimeFormat or relative to now if the field has the reltime tag option
+func formatTime(v reflect.Value, fi *fieldInfo, o *options) (string, error) {
+	if !v.CanInterface() {
+		return "", fmt.Errorf("unable to access unexported time.Time")
+	}
+	t := v.Interface().(time.Time)
+
+	if fi.opts.has("reltime") && !(o.csv && o.csvAbsoluteTime) {
+		return relativeTime(t, o.now()), nil
+	}
+	return t.Format(timeLayout(fi, o)), nil
+}
+
+// timeLayout returns the layout of the timefmt tag option, WithTimeFormat
+// or RFC3339
+func timeLayout(fi *fieldInfo, o *options) string {
+	layout := time.RFC3339
+	if o.timeFormat != "" {
+		layout = o.timeFormat
+	}
+	if fi.opts.has("timefmt") {
+		layout = fi.opts["timefmt"]
+		if named, ok := timeLayouts[layout]; ok {
+			layout = named
+		}
+	}
+	return layout
+}
+
+// formatDuration renders a time.Duration like 1m30s or in the unit of the
+// unit tag option, such as `header:"Took,unit=ms"` => 90000ms.  The unit is
+// validated by newFieldInfo.
+func formatDuration(v reflect.Value, fi *fieldInfo) string {
+	d := time.Duration(v.Int())
+	if !fi.opts.has("unit") {
+		return d.String()
+	}
+	unit := durationUnits[fi.opts["unit"]]
+	return strconv.FormatFloat(float64(d)/float64(unit), 'f', -1, 64) + fi.opts["unit"]
+}
+
+// relativeTime returns t relative to now: "3h ago" or "in 2d"
+func relativeTime(t, now time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+
+	var ret string
+	switch {
+	case d < time.Minute:
+		ret = fmt.Sprintf("%ds", int64(d/time.Second))
+	case d < time.Hour:
+		ret = fmt.Sprintf("%dm", int64(d/time.Minute))
+	case d < 48*time.Hour:
+		ret = fmt.Sprintf("%dh", int64(d/time.Hour))
+	default:
+		ret = fmt.Sprintf("%dd", int64(d/(24*time.Hour)))
+	}
+
+	if future {
+		return "in " + ret
+	}
+	return ret + " ago"
+}
@@ -0,0 +1,198 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"bytes"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type seenRow struct {
+	Host     string    `header:"Host"`
+	LastSeen time.Time `header:"LastSeen,reltime"`
+}
+
+func (r seenRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2021, 3, 4, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		t    time.Time
+		want string
+	}{
+		{time.Time{}, "never"},
+		{now, "0s ago"},
+		{now.Add(-59 * time.Second), "59s ago"},
+		{now.Add(-time.Minute), "1m ago"},
+		{now.Add(-59 * time.Minute), "59m ago"},
+		{now.Add(-3 * time.Hour), "3h ago"},
+		{now.Add(-47 * time.Hour), "47h ago"},
+		{now.Add(-48 * time.Hour), "2d ago"},
+		{now.Add(30 * time.Second), "in 30s"},
+		{now.Add(50 * time.Hour), "in 2d"},
+	}
+	for _, tt := range tests {
+		if got := relativeTime(tt.t, now); got != tt.want {
+			t.Errorf("relativeTime(%s) = %q, want %q", tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestRelativeTimeCSV(t *testing.T) {
+	now := time.Date(2021, 3, 4, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	rows := []TableStruct{seenRow{Host: "a", LastSeen: now.Add(-3 * time.Hour)}}
+	tests := []struct {
+		name   string
+		format Format
+		opts   []Option
+		want   string
+	}{
+		{"table", FormatTable, []Option{WithClock(clock), WithCSVAbsoluteTime()}, "Host | LastSeen\n===============\na    | 3h ago  \n"},
+		{"csv", FormatCSV, []Option{WithClock(clock)}, "a,3h ago\n"},
+		{"csv absolute", FormatCSV, []Option{WithClock(clock), WithCSVAbsoluteTime()}, "a,2021-03-04T09:00:00Z\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := RenderMulti(rows, nil, []Output{{Format: tt.format, Writer: &buf}}, tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("RenderMulti() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+type eventRow struct {
+	Name    string       `header:"Name"`
+	When    time.Time    `header:"When"`
+	Day     time.Time    `header:"Day,timefmt=date"`
+	Deleted sql.NullTime `header:"Deleted"`
+}
+
+func (r eventRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestTimeFormatOutputs(t *testing.T) {
+	when := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	rows := []TableStruct{eventRow{Name: "a", When: when, Day: when}}
+	opts := []Option{WithTimeFormat(time.Kitchen), WithNullPlaceholder("-"), WithCSVHeader()}
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{FormatTable, "Name | When   | Day        | Deleted\n====================================\na    | 5:06AM | 2021-03-04 | -      \n"},
+		{FormatCSV, "Name,When,Day,Deleted\na,5:06AM,2021-03-04,-\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := RenderMulti(rows, nil, []Output{{Format: tt.format, Writer: &buf}}, opts...); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("RenderMulti(%d) = %q, want %q", tt.format, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestTypeFormatterCSV(t *testing.T) {
+	withFormatters(t)
+	RegisterTypeFormatter(reflect.TypeOf(time.Time{}), func(v reflect.Value) (string, error) {
+		return v.Interface().(time.Time).Format("Jan 2"), nil
+	})
+	when := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	var buf bytes.Buffer
+	err := RenderMulti([]TableStruct{timeRow{When: when}}, nil, []Output{{Format: FormatCSV, Writer: &buf}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Mar 4\n"; buf.String() != want {
+		t.Errorf("RenderMulti() = %q, want %q", buf.String(), want)
+	}
+}
+
+type tookRow struct {
+	MS    time.Duration `header:"MS,unit=ms"`
+	Hours time.Duration `header:"Hours,unit=h"`
+	Plain time.Duration `header:"Plain"`
+}
+
+func (r tookRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+type badUnitRow struct {
+	Took time.Duration `header:"Took,unit=days"`
+}
+
+func (r badUnitRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestDurationUnit(t *testing.T) {
+	d := 90 * time.Minute
+	got, err := GenerateTableString([]TableStruct{tookRow{MS: d, Hours: d, Plain: d}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "       MS | Hours | Plain  \n===========================\n5400000ms |  1.5h | 1h30m0s\n"
+	if got != want {
+		t.Errorf("GenerateTableString() = %q, want %q", got, want)
+	}
+
+	_, err = GenerateTableString([]TableStruct{badUnitRow{}}, nil)
+	if want := "Invalid unit 'days' for field 'Took' in badUnitRow"; err == nil || err.Error() != want {
+		t.Errorf("GenerateTableString() error = %v, want %q", err, want)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		unit string
+		want string
+	}{
+		{0, "", "0s"},
+		{1500 * time.Millisecond, "", "1.5s"},
+		{-90 * time.Second, "", "-1m30s"},
+		{1500 * time.Millisecond, "ms", "1500ms"},
+		{1500 * time.Microsecond, "us", "1500us"},
+		{1500 * time.Microsecond, "µs", "1500µs"},
+		{1500 * time.Microsecond, "ms", "1.5ms"},
+		{42, "ns", "42ns"},
+		{90 * time.Second, "m", "1.5m"},
+		{-36 * time.Hour, "h", "-36h"},
+	}
+	for _, tt := range tests {
+		fi := &fieldInfo{opts: tagOptions{}}
+		if tt.unit != "" {
+			fi.opts["unit"] = tt.unit
+		}
+		if got := formatDuration(reflect.ValueOf(tt.d), fi); got != tt.want {
+			t.Errorf("formatDuration(%s, %q) = %q, want %q", tt.d, tt.unit, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"io"
+	"strings"
+)
+
+// printTitle prints each line of the WithTitle title centered over the
+// table, followed by the underline if one was set
+func printTitle(w io.Writer, colWidth []int, o *options) error {
+	if o.title == "" {
+		return nil
+	}
+	width := tableWidth(colWidth, o)
+	lines := []string{}
+	for _, line := range strings.Split(o.title, "\n") {
+		lines = append(lines, centerLeft(line, width))
+		if o.titleUnderline != "" {
+			underline := repeatWidth(o.titleUnderline, displayWidth(line))
+			lines = append(lines, centerLeft(underline, width))
+		}
+	}
+	return printLines(w, lines)
+}
+
+// tableWidth returns the width of a line of the table
+func tableWidth(colWidth []int, o *options) int {
+	width := separatorWidth(len(colWidth), o)
+	for _, cw := range colWidth {
+		width += cw
+	}
+	return width
+}
+
+// centerLeft returns value with the left padding needed to center it in width
+func centerLeft(value string, width int) string {
+	return strings.TrimRight(center(value, width), " ")
+}
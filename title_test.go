@@ -0,0 +1,63 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"testing"
+)
+
+func TestTitle(t *testing.T) {
+	rows := []TableStruct{colorRow{Name: "a", Value: "b"}}
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{
+			name: "none",
+			opts: []Option{WithTitle("")},
+			want: "Name | Value\n============\na    | b    \n",
+		},
+		{
+			name: "centered",
+			opts: []Option{WithTitle("Hosts")},
+			want: "   Hosts\nName | Value\n============\na    | b    \n",
+		},
+		{
+			name: "multi-line",
+			opts: []Option{WithTitle("Hosts\nby name"), WithTitleUnderline("-")},
+			want: "   Hosts\n   -----\n  by name\n  -------\nName | Value\n============\na    | b    \n",
+		},
+		{
+			name: "wider than the table",
+			opts: []Option{WithTitle("All the hosts")},
+			want: "All the hosts\nName | Value\n============\na    | b    \n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateTableString(rows, nil, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("GenerateTableString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"fmt"
+	"io"
+)
+
+// Generates a table with the rows & columns swapped: the first column
+// holds the headers and each following column is a row.  Useful to compare
+// a few rows side by side.
+func GenerateTransposed(w io.Writer, tables []TableStruct, fields []string, opts ...Option) error {
+	o := newOptions(opts)
+	o.table = true
+	t, err := buildTable(tables, fields, o)
+	if err != nil {
+		return err
+	}
+
+	return generateTable(w, transpose(t), o)
+}
+
+// transpose returns a copy of t with the rows as columns.  The result has
+// no header line.
+func transpose(t *tableData) *tableData {
+	ret := &tableData{
+		fields:   make([]string, len(t.rows)+1),
+		headers:  make([]string, len(t.rows)+1),
+		rows:     make([][]string, len(t.fields)),
+		opts:     make([]tagOptions, len(t.rows)+1),
+		noHeader: true,
+	}
+	for i := range t.rows {
+		ret.fields[i+1] = fmt.Sprintf("%d", i+1)
+	}
+
+	for i, header := range t.headers {
+		row := make([]string, len(t.rows)+1)
+		row[0] = header
+		for j, values := range t.rows {
+			row[j+1] = values[i]
+		}
+		ret.rows[i] = row
+	}
+	return ret
+}
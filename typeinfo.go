@@ -0,0 +1,76 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"reflect"
+	"sync"
+)
+
+// typeInfo is the reflection metadata of a struct type which is the same
+// for every row
+type typeInfo struct {
+	fields []reflect.StructField
+	infos  []*fieldInfo
+	errs   []error
+}
+
+// the metadata also depends on the tag key & how fields are excluded
+type typeInfoKey struct {
+	t           reflect.Type
+	tagKey      string
+	tagFallback bool
+}
+
+var (
+	typeInfoLock  sync.RWMutex
+	typeInfoCache = map[typeInfoKey]*typeInfo{}
+)
+
+// getTypeInfo returns the metadata of the struct type t, which is only
+// calculated the first time t is seen
+func getTypeInfo(t reflect.Type, o *options) *typeInfo {
+	key := typeInfoKey{t: t, tagKey: HeaderTagKey(), tagFallback: o.tagFallback}
+
+	typeInfoLock.RLock()
+	ti, ok := typeInfoCache[key]
+	typeInfoLock.RUnlock()
+	if ok {
+		return ti
+	}
+
+	ti = &typeInfo{fields: visibleFields(t, o)}
+	ti.infos = make([]*fieldInfo, len(ti.fields))
+	ti.errs = make([]error, len(ti.fields))
+	for i, f := range ti.fields {
+		ti.infos[i], ti.errs[i] = newFieldInfo(t, f)
+	}
+
+	typeInfoLock.Lock()
+	typeInfoCache[key] = ti
+	typeInfoLock.Unlock()
+	return ti
+}
+
+// resetTypeInfo clears the cache after a change which affects the metadata,
+// such as RegisterEnum
+func resetTypeInfo() {
+	typeInfoLock.Lock()
+	defer typeInfoLock.Unlock()
+	typeInfoCache = map[typeInfoKey]*typeInfo{}
+}
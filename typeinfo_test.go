@@ -0,0 +1,61 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTypeInfoCache(t *testing.T) {
+	o := newOptions(nil)
+	rowType := reflect.TypeOf(benchRow{})
+	first := getTypeInfo(rowType, o)
+	if second := getTypeInfo(rowType, o); second != first {
+		t.Errorf("getTypeInfo() not cached")
+	}
+	if fallback := getTypeInfo(rowType, newOptions([]Option{WithTagFallback()})); fallback == first {
+		t.Errorf("getTypeInfo(WithTagFallback) shares the default entry")
+	}
+	resetTypeInfo()
+	if again := getTypeInfo(rowType, o); again == first {
+		t.Errorf("getTypeInfo() not recalculated after resetTypeInfo")
+	}
+}
+
+// BenchmarkTableRow compares converting rows with the cached type metadata
+// to rebuilding it for every row as was done before the cache
+func BenchmarkTableRow(b *testing.B) {
+	row := benchRows(1)[0]
+	o := newOptions(nil)
+	b.Run("cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, _, err := tableRow(row, nil, o); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			resetTypeInfo()
+			if _, _, err := tableRow(row, nil, o); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
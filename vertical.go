@@ -0,0 +1,105 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"fmt"
+	"io"
+)
+
+// Generates a record oriented output like MySQL's \G with a line for each
+// field of each row: `header: value`
+func GenerateVertical(w io.Writer, tables []TableStruct, fields []string, opts ...Option) error {
+	o := newOptions(opts)
+	o.table = true
+	t, err := buildTable(tables, fields, o)
+	if err != nil {
+		return err
+	}
+	return generateVertical(w, t)
+}
+
+// GenerateVerticalOne is GenerateVertical for a single row, which often
+// reads better than a table with a single row
+func GenerateVerticalOne(w io.Writer, table TableStruct, fields []string, opts ...Option) error {
+	return GenerateVertical(w, []TableStruct{table}, fields, opts...)
+}
+
+func generateVertical(w io.Writer, t *tableData) error {
+	width := headerWidth(t.headers)
+	for i, row := range t.rows {
+		if err := printRecord(w, fmt.Sprintf("*** row %d ***", i+1), t.headers, row, width); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generateVerticalFallback renders a table too wide for WithVerticalFallback
+// like GenerateVertical, keeping the title, aggregates & row count
+func generateVerticalFallback(w io.Writer, t *tableData, o *options) error {
+	if err := printTitle(w, []int{}, o); err != nil {
+		return err
+	}
+	if err := generateVertical(w, t); err != nil {
+		return err
+	}
+	if t.footer != nil {
+		// only the columns with an aggregate
+		headers, values := []string{}, []string{}
+		for i, value := range t.footer {
+			if value != "" {
+				headers = append(headers, t.headers[i])
+				values = append(values, value)
+			}
+		}
+		if err := printRecord(w, "*** total ***", headers, values, headerWidth(t.headers)); err != nil {
+			return err
+		}
+	}
+	if o.rowCount {
+		_, err := fmt.Fprintln(w, rowCountLine(len(t.rows)))
+		return err
+	}
+	return nil
+}
+
+// printRecord prints the title line of a record followed by a line for
+// each value with the headers right aligned to width
+func printRecord(w io.Writer, title string, headers, values []string, width int) error {
+	if _, err := fmt.Fprintln(w, title); err != nil {
+		return err
+	}
+	for j, value := range values {
+		if _, err := fmt.Fprintf(w, "%s: %s\n", pad(headers[j], width, AlignRight, " "), value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// headerWidth returns the width of the widest header
+func headerWidth(headers []string) int {
+	width := 0
+	for _, header := range headers {
+		if l := displayWidth(header); l > width {
+			width = l
+		}
+	}
+	return width
+}
@@ -0,0 +1,130 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+type priceRow struct {
+	Name  string  `header:"Name"`
+	Price float64 `header:"Price,align=decimal,agg=sum"`
+}
+
+func (r priceRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestVerticalFallback(t *testing.T) {
+	rows := []TableStruct{
+		priceRow{Name: "a long name here", Price: 1.5},
+		priceRow{Name: "b", Price: 10.25},
+	}
+	records := "*** row 1 ***\n Name: a long name here\nPrice: 1.5\n*** row 2 ***\n Name: b\nPrice: 10.25\n"
+
+	var buf bytes.Buffer
+	if err := GenerateVertical(&buf, rows, nil); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != records {
+		t.Errorf("GenerateVertical() = %q, want %q", buf.String(), records)
+	}
+
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{
+			name: "fits",
+			opts: []Option{WithVerticalFallback(), WithTotalWidth(80)},
+			want: "Name             | Price\n========================\na long name here |  1.5 \nb                | 10.25\n-----------------+------\n                 | 11.75\n",
+		},
+		{
+			name: "too wide",
+			opts: []Option{WithVerticalFallback(), WithTotalWidth(10)},
+			want: records + "*** total ***\nPrice: 11.75\n",
+		},
+		{
+			name: "title & row count",
+			opts: []Option{WithVerticalFallback(), WithTotalWidth(10), WithTitle("Prices"), WithRowCount()},
+			want: "Prices\n" + records + "*** total ***\nPrice: 11.75\n(2 rows)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateTableString(rows, nil, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("GenerateTableString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateVertical(t *testing.T) {
+	rows := []TableStruct{
+		statusRow{OK: "yes", Count: 1, Name: "a"},
+		statusRow{OK: "no", Count: 22, Name: "名前"},
+	}
+	tests := []struct {
+		name   string
+		fields []string
+		opts   []Option
+		want   string
+	}{
+		{
+			name: "all fields",
+			want: "*** row 1 ***\n  OK: yes\n   N: 1\nName: a\n*** row 2 ***\n  OK: no\n   N: 22\nName: 名前\n",
+		},
+		{
+			name:   "selected fields",
+			fields: []string{"Name", "Count"},
+			want:   "*** row 1 ***\nName: a\n   N: 1\n*** row 2 ***\nName: 名前\n   N: 22\n",
+		},
+		{
+			name:   "header options",
+			fields: []string{"OK"},
+			opts:   []Option{WithHeaderOverrides(map[string]string{"OK": "Healthy"})},
+			want:   "*** row 1 ***\nHealthy: yes\n*** row 2 ***\nHealthy: no\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := GenerateVertical(&buf, rows, tt.fields, tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("GenerateVertical() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := GenerateVertical(&buf, []TableStruct{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "" {
+		t.Errorf("GenerateVertical() = %q for no rows", buf.String())
+	}
+}
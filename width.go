@@ -0,0 +1,418 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// Truncate selects which part of a value is replaced by the ellipsis when
+// it is truncated to its max width
+type Truncate int
+
+const (
+	// TruncateEnd keeps the start of the value: "abcd…"
+	TruncateEnd Truncate = iota
+	// TruncateStart keeps the end of the value: "…/pkg/gotable.go"
+	TruncateStart
+	// TruncateMiddle keeps both ends of the value: "abc…xyz"
+	TruncateMiddle
+)
+
+// truncation modes accepted by the trunc tag option
+var truncateNames = map[string]Truncate{
+	"end":    TruncateEnd,
+	"start":  TruncateStart,
+	"middle": TruncateMiddle,
+}
+
+// ColumnWidths returns the width of each column GenerateTable would use
+// for the given fields without rendering the table
+func ColumnWidths(tables []TableStruct, fields []string, opts ...Option) ([]int, error) {
+	o := newOptions(opts)
+	o.table = true
+	t, err := buildTable(tables, fields, o)
+	if err != nil {
+		return []int{}, err
+	}
+	fitTerminal(os.Stdout, o)
+	return columnWidths(alignDecimals(escapeTable(t, o)), o), nil
+}
+
+// fitTerminal sets the total width to the width of the terminal w is
+// connected to if WithTerminalWidth is set.  Falls back to $COLUMNS, with
+// WithColumnsEnv, & then the default width when w is not a terminal.
+func fitTerminal(w io.Writer, o *options) {
+	if !o.terminalWidth || o.totalWidth > 0 {
+		return
+	}
+	if f, ok := w.(*os.File); ok {
+		if width, ok := terminalWidth(f); ok {
+			o.totalWidth = width
+			return
+		}
+	}
+	if o.columnsEnv {
+		if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+			o.totalWidth = width
+			return
+		}
+	}
+	o.totalWidth = o.defaultWidth
+}
+
+// fits returns true if the table fits in WithTotalWidth at its natural width
+func fits(t *tableData, o *options) bool {
+	if o.totalWidth <= 0 {
+		return true
+	}
+	natural := *o
+	natural.totalWidth = 0
+	return tableWidth(columnWidths(t, &natural), o) <= o.totalWidth
+}
+
+// columnWidths returns the width of each column needed to fit the header
+// and every value, adjusted by the options
+func columnWidths(t *tableData, o *options) []int {
+	colWidth := make([]int, len(t.fields))
+
+	// caller supplied widths replace the calculated ones
+	if len(o.columnWidths) < len(colWidth) {
+		naturalWidths(t, colWidth, o)
+		for i := range colWidth {
+			if limit := maxWidth(t, i, o); limit > 0 && colWidth[i] > limit {
+				colWidth[i] = limit
+			}
+			if o.fullHeaders && colWidth[i] < displayWidth(t.headers[i]) {
+				colWidth[i] = displayWidth(t.headers[i])
+			}
+			if floor := minWidth(t, i, o); colWidth[i] < floor {
+				colWidth[i] = floor
+			}
+			if width := fixedWidth(t, i, o); width > 0 {
+				colWidth[i] = width
+			}
+		}
+	}
+	for i, width := range o.columnWidths {
+		if i < len(colWidth) {
+			colWidth[i] = width
+		}
+	}
+
+	if o.totalWidth > 0 {
+		fitWidths(colWidth, t, o.totalWidth-separatorWidth(len(colWidth), o), o)
+	}
+	return colWidth
+}
+
+// naturalWidths sets colWidth to the width needed for the header and the
+// longest value of each column.  Wrapped & multi-line columns only need the
+// width of the longest line and fixed width columns are skipped.
+func naturalWidths(t *tableData, colWidth []int, o *options) {
+	fixed := make([]bool, len(colWidth))
+	for i := range fixed {
+		fixed[i] = fixedWidth(t, i, o) > 0
+	}
+
+	// figure out width of column headers
+	for i, header := range t.headers {
+		colWidth[i] = displayWidth(header)
+	}
+
+	// calc max len of every column
+	rows := t.rows
+	if t.footer != nil {
+		rows = append(rows[:len(rows):len(rows)], t.footer)
+	}
+	for _, row := range rows {
+		for i, value := range row {
+			if fixed[i] {
+				continue
+			}
+			width := displayWidth(value)
+			if multiLine(t, i, o) {
+				width = lineWidth(value)
+			}
+			if width > colWidth[i] {
+				colWidth[i] = width
+			}
+		}
+	}
+}
+
+// separatorWidth is the number of characters between columns
+func separatorWidth(columns int, o *options) int {
+	if columns < 2 {
+		return 0
+	}
+	return (columns - 1) * displayWidth(o.columnSeparator())
+}
+
+// fitWidths shrinks the columns so the sum of colWidth fits in available,
+// starting with the lowest priority columns.  Columns of a higher priority
+// are only shrunk once the lower ones are at their floor.  Fixed width
+// columns are never shrunk.
+func fitWidths(colWidth []int, t *tableData, available int, o *options) {
+	levels := []int{}
+	seen := map[int]bool{}
+	for i := range colWidth {
+		if p := priority(t, i); !seen[p] {
+			seen[p] = true
+			levels = append(levels, p)
+		}
+	}
+	sort.Ints(levels)
+	for _, level := range levels {
+		candidates := make([]bool, len(colWidth))
+		for i := range candidates {
+			candidates[i] = priority(t, i) == level && fixedWidth(t, i, o) == 0
+		}
+		shrinkWidths(colWidth, t, candidates, available, o)
+	}
+}
+
+// shrinkWidths reduces the widest candidate columns proportionally so the
+// sum of colWidth fits in available.  Columns narrower than their fair
+// share are left alone and no column is shrunk below its floor or min width.
+func shrinkWidths(colWidth []int, t *tableData, candidates []bool, available int, o *options) {
+	total, remaining := 0, available
+	for i, width := range colWidth {
+		total += width
+		if !candidates[i] {
+			remaining -= width
+		}
+	}
+	if total <= available {
+		return
+	}
+
+	// columns which fit in their fair share keep their width and give
+	// the remaining space to the others
+	shrink := make([]bool, len(colWidth))
+	copy(shrink, candidates)
+	for {
+		cnt := 0
+		for _, s := range shrink {
+			if s {
+				cnt++
+			}
+		}
+		if cnt == 0 {
+			return
+		}
+		share := remaining / cnt
+		changed := false
+		for i, width := range colWidth {
+			if shrink[i] && width <= share {
+				shrink[i] = false
+				remaining -= width
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	original := make([]int, len(colWidth))
+	copy(original, colWidth)
+	wide := 0
+	for i, width := range colWidth {
+		if shrink[i] {
+			wide += width
+		}
+	}
+	if wide == 0 {
+		return // nothing left to shrink at this level
+	}
+	budget := remaining
+	if budget < 0 {
+		budget = 0 // the other columns already exceed available
+	}
+	for i, width := range colWidth {
+		if !shrink[i] {
+			continue
+		}
+		newWidth := width * budget / wide
+		floor := o.shrinkFloor
+		if floor == 0 {
+			floor = displayWidth(t.headers[i])
+		}
+		if width := minWidth(t, i, o); floor < width {
+			floor = width
+		}
+		if o.fullHeaders && floor < displayWidth(t.headers[i]) {
+			floor = displayWidth(t.headers[i])
+		}
+		if newWidth < floor {
+			newWidth = floor
+		}
+		if newWidth < width {
+			colWidth[i] = newWidth
+		}
+		remaining -= colWidth[i]
+	}
+
+	// hand out any space lost to rounding
+	for i := range colWidth {
+		if remaining <= 0 {
+			break
+		}
+		if shrink[i] && colWidth[i] < original[i] {
+			colWidth[i]++
+			remaining--
+		}
+	}
+}
+
+// maxWidth returns the maxwidth or wrap=N tag option of column i or the
+// WithMaxColumnWidth default.  Zero is unlimited.
+func maxWidth(t *tableData, i int, o *options) int {
+	if i < len(t.opts) && t.opts[i].has("maxwidth") {
+		width, _ := strconv.Atoi(t.opts[i]["maxwidth"])
+		return width
+	}
+	if width := wrapWidth(t, i); width > 0 {
+		return width
+	}
+	return o.maxColumnWidth
+}
+
+// priority returns the priority tag option of column i.  The index column
+// always has the highest priority.
+func priority(t *tableData, i int) int {
+	if t.hasIndex && i == 0 {
+		return math.MaxInt32
+	}
+	if i < len(t.opts) {
+		p, _ := strconv.Atoi(t.opts[i]["priority"])
+		return p
+	}
+	return 0
+}
+
+// dropColumns removes the lowest priority columns, rightmost first, until
+// the table fits in WithTotalWidth.  Columns are only dropped if one of
+// them has the priority tag option.  Returns the number of columns dropped.
+func dropColumns(t *tableData, o *options) int {
+	if o.totalWidth <= 0 {
+		return 0
+	}
+	found := false
+	for _, opts := range t.opts {
+		found = found || opts.has("priority")
+	}
+	if !found {
+		return 0
+	}
+
+	keep := 1
+	if t.hasIndex {
+		keep = 2
+	}
+	dropped := 0
+	for len(t.fields) > keep && tableWidth(columnWidths(t, o), o) > o.totalWidth {
+		drop := len(t.fields) - 1
+		for i := drop - 1; i >= keep-1; i-- {
+			if priority(t, i) < priority(t, drop) {
+				drop = i
+			}
+		}
+		t.removeColumn(drop)
+		dropped++
+	}
+	return dropped
+}
+
+// hiddenLine is the note printed after the table for dropped columns
+func hiddenLine(columns int) string {
+	if columns == 1 {
+		return "(+1 column hidden)"
+	}
+	return fmt.Sprintf("(+%d columns hidden)", columns)
+}
+
+// fixedWidth returns the width tag option of column i or its WithFixedWidths
+// width.  Zero is not fixed.
+func fixedWidth(t *tableData, i int, o *options) int {
+	if i < len(t.opts) && t.opts[i].has("width") {
+		width, _ := strconv.Atoi(t.opts[i]["width"])
+		return width
+	}
+	if i < len(t.fields) {
+		return o.fixedWidths[t.fields[i]]
+	}
+	return 0
+}
+
+// minWidth returns the minwidth tag option of column i or the
+// WithMinColumnWidth default
+func minWidth(t *tableData, i int, o *options) int {
+	if i < len(t.opts) && t.opts[i].has("minwidth") {
+		width, _ := strconv.Atoi(t.opts[i]["minwidth"])
+		return width
+	}
+	return o.minColumnWidth
+}
+
+// truncateCell returns the value of column i limited to width.  Columns with
+// a max or fixed width or the trunc tag option are truncated with the
+// ellipsis.
+func truncateCell(t *tableData, i int, value string, width int, o *options) string {
+	mode := o.truncation
+	if i < len(t.opts) && t.opts[i].has("trunc") {
+		mode = truncateNames[t.opts[i]["trunc"]]
+	} else if maxWidth(t, i, o) == 0 && fixedWidth(t, i, o) == 0 {
+		return truncate(value, width)
+	}
+	return truncateEllipsis(value, width, o.ellipsis, mode)
+}
+
+// truncateEllipsis returns value limited to width cells with the ellipsis
+// replacing the characters removed from the end, start or middle
+func truncateEllipsis(value string, width int, ellipsis string, mode Truncate) string {
+	if displayWidth(value) <= width {
+		return value
+	}
+	keep := width - displayWidth(ellipsis)
+	if keep < 1 {
+		return truncate(value, width)
+	}
+	switch mode {
+	case TruncateStart:
+		return ellipsis + tailWidth(value, keep)
+	case TruncateMiddle:
+		front := keep - keep/2
+		return headWidth(value, front) + ellipsis + tailWidth(value, keep/2)
+	}
+	return headWidth(value, keep) + ellipsis
+}
+
+// truncate returns value limited to width cells
+func truncate(value string, width int) string {
+	if displayWidth(value) <= width {
+		return value
+	}
+	return headWidth(value, width)
+}
@@ -0,0 +1,558 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type fixedRow struct {
+	Long  string `header:"Long,width=50"`
+	Empty string `header:""`
+}
+
+func (r fixedRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestShrinkEmptyColumn(t *testing.T) {
+	rows := []TableStruct{fixedRow{Long: "value"}}
+	widths, err := ColumnWidths(rows, nil, WithTotalWidth(20), WithNoHeaderFallback())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(widths, []int{50, 0}) {
+		t.Errorf("ColumnWidths() = %v, want [50 0]", widths)
+	}
+}
+
+type decimalRow struct {
+	Value float64 `header:"V,align=decimal"`
+}
+
+func (r decimalRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestColumnWidthsDecimal(t *testing.T) {
+	rows := []TableStruct{decimalRow{1.5}, decimalRow{10}}
+	widths, err := ColumnWidths(rows, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(widths, []int{4}) {
+		t.Errorf("ColumnWidths() = %v, want [4]", widths)
+	}
+
+	// the widths must render every value in full
+	got, err := GenerateTableString(rows, nil, WithColumnWidths(widths))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "   V\n====\n 1.5\n10  \n"
+	if got != want {
+		t.Errorf("GenerateTableString() = %q, want %q", got, want)
+	}
+}
+
+func TestFitTerminalNotTTY(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns string
+		opts    []Option
+		want    int
+	}{
+		{"natural", "40", []Option{WithTerminalFit()}, 0},
+		{"default width", "40", []Option{WithTerminalWidth(60)}, 60},
+		{"columns env", "40", []Option{WithTerminalFit(), WithColumnsEnv()}, 40},
+		{"columns env unset", "", []Option{WithTerminalWidth(60), WithColumnsEnv()}, 60},
+		{"total width", "40", []Option{WithTerminalFit(), WithColumnsEnv(), WithTotalWidth(30)}, 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Setenv("COLUMNS", tt.columns)
+			defer os.Unsetenv("COLUMNS")
+			o := newOptions(tt.opts)
+			fitTerminal(&bytes.Buffer{}, o)
+			if o.totalWidth != tt.want {
+				t.Errorf("totalWidth = %d, want %d", o.totalWidth, tt.want)
+			}
+		})
+	}
+}
+
+type truncRow struct {
+	Path string `header:"Path,maxwidth=8,trunc=start"`
+	ID   string `header:"ID,maxwidth=7,trunc=middle"`
+	Note string `header:"Note,maxwidth=6"`
+}
+
+func (r truncRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestTruncateEllipsis(t *testing.T) {
+	tests := []struct {
+		value  string
+		end    string
+		start  string
+		middle string
+	}{
+		{"abcdef", "abcdef", "abcdef", "abcdef"},  // exactly the limit
+		{"abcdefg", "abcde…", "…cdefg", "abc…fg"}, // one rune over
+		{"abcdefgh", "abcde…", "…defgh", "abc…gh"},
+		{"héllöwörld", "héllö…", "…wörld", "hél…ld"},
+		{"名前名前", "名前…", "…名前", "名…前"},
+	}
+	for _, tt := range tests {
+		for mode, want := range map[Truncate]string{TruncateEnd: tt.end, TruncateStart: tt.start, TruncateMiddle: tt.middle} {
+			if got := truncateEllipsis(tt.value, 6, "…", mode); got != want {
+				t.Errorf("truncateEllipsis(%q, %d) = %q, want %q", tt.value, mode, got, want)
+			}
+		}
+	}
+}
+
+func TestTruncTag(t *testing.T) {
+	rows := []TableStruct{truncRow{Path: "/src/pkg/gotable.go", ID: "abc123xyz", Note: "abcdefg"}}
+	got, err := GenerateTableString(rows, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Path     | ID      | Note  \n===========================\n…able.go | abc…xyz | abcde…\n"
+	if got != want {
+		t.Errorf("GenerateTableString() = %q, want %q", got, want)
+	}
+}
+
+type statusRow struct {
+	OK    string `header:"OK,minwidth=4"`
+	Count int    `header:"N,minwidth=3"`
+	Name  string `header:"Name"`
+}
+
+func (r statusRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestMinColumnWidth(t *testing.T) {
+	tests := []struct {
+		name   string
+		rows   []TableStruct
+		fields []string
+		opts   []Option
+		want   string
+	}{
+		{
+			name: "min exceeds values",
+			rows: []TableStruct{statusRow{OK: "✓", Count: 1, Name: "a"}},
+			want: "OK   |   N | Name\n=================\n✓    |   1 | a   \n",
+		},
+		{
+			name: "values exceed min",
+			rows: []TableStruct{statusRow{OK: "✓✓✓✓✓", Count: 12345, Name: "a"}},
+			want: "OK    |     N | Name\n====================\n✓✓✓✓✓ | 12345 | a   \n",
+		},
+		{
+			name: "global default",
+			rows: []TableStruct{statusRow{OK: "✓", Count: 1, Name: "a"}},
+			opts: []Option{WithMinColumnWidth(6)},
+			want: "OK   |   N | Name  \n===================\n✓    |   1 | a     \n",
+		},
+		{
+			// a typed nil row defines the headers of a table without rows
+			name:   "empty table",
+			rows:   []TableStruct{(*statusRow)(nil)},
+			fields: []string{"OK", "Count"},
+			want:   "OK   |   N\n==========\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateTableString(tt.rows, tt.fields, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("GenerateTableString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTotalWidth(t *testing.T) {
+	rows := []TableStruct{
+		colorRow{Name: "a very long name value", Value: "another long value here"},
+		colorRow{Name: "b", Value: "c"},
+	}
+	tests := []struct {
+		width int
+		want  string
+	}{
+		{0, "Name                   | Value                  \n================================================\na very long name value | another long value here\nb                      | c                      \n"},
+		{40, "Name                | Value             \n========================================\na very long name va | another long value\nb                   | c                 \n"},
+		{30, "Name           | Value        \n==============================\na very long na | another long \nb              | c            \n"},
+		// columns are never shrunk below their header
+		{12, "Name | Value\n============\na ve | anoth\nb    | c    \n"},
+		{5, "Name | Value\n============\na ve | anoth\nb    | c    \n"},
+	}
+	for _, tt := range tests {
+		got, err := GenerateTableString(rows, nil, WithTotalWidth(tt.width))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("WithTotalWidth(%d) = %q, want %q", tt.width, got, tt.want)
+		}
+	}
+}
+
+// widthColumn is a column of the tables built by widthTable
+type widthColumn struct {
+	header string
+	width  int // width of the only value
+	opts   tagOptions
+}
+
+// widthTable returns a table with one row of values of the given widths,
+// prefixed by a 1 wide index column if index is set
+func widthTable(index bool, cols ...widthColumn) *tableData {
+	if index {
+		cols = append([]widthColumn{{header: "#", width: 1}}, cols...)
+	}
+	t := &tableData{rows: [][]string{{}}, hasIndex: index}
+	for _, col := range cols {
+		opts := col.opts
+		if opts == nil {
+			opts = tagOptions{}
+		}
+		t.fields = append(t.fields, col.header)
+		t.headers = append(t.headers, col.header)
+		t.opts = append(t.opts, opts)
+		t.aligns = append(t.aligns, AlignLeft)
+		t.rows[0] = append(t.rows[0], strings.Repeat("x", col.width))
+	}
+	return t
+}
+
+func TestFitWidths(t *testing.T) {
+	tests := []struct {
+		name      string
+		index     bool
+		cols      []widthColumn
+		available int
+		want      []int
+	}{
+		{
+			name:      "fits",
+			cols:      []widthColumn{{"A", 5, nil}, {"B", 5, nil}},
+			available: 10,
+			want:      []int{5, 5},
+		},
+		{
+			name:      "lowest priority shrunk first",
+			cols:      []widthColumn{{"A", 10, tagOptions{"priority": "1"}}, {"B", 10, tagOptions{"priority": "2"}}},
+			available: 15,
+			want:      []int{5, 10},
+		},
+		{
+			name:      "higher priority shrunk once lower is at its floor",
+			cols:      []widthColumn{{"AAAA", 10, tagOptions{"priority": "1"}}, {"B", 10, tagOptions{"priority": "2"}}},
+			available: 8,
+			want:      []int{4, 4},
+		},
+		{
+			name:      "fixed width never shrunk",
+			cols:      []widthColumn{{"A", 10, tagOptions{"width": "10"}}, {"B", 10, nil}},
+			available: 12,
+			want:      []int{10, 2},
+		},
+		{
+			name:      "index column shrunk last",
+			index:     true,
+			cols:      []widthColumn{{"A", 10, tagOptions{"priority": "5"}}},
+			available: 6,
+			want:      []int{1, 5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newOptions(nil)
+			tbl := widthTable(tt.index, tt.cols...)
+			colWidth := []int{}
+			for _, row := range tbl.rows[0] {
+				colWidth = append(colWidth, len(row))
+			}
+			fitWidths(colWidth, tbl, tt.available, o)
+			if !reflect.DeepEqual(colWidth, tt.want) {
+				t.Errorf("fitWidths() = %v, want %v", colWidth, tt.want)
+			}
+		})
+	}
+}
+
+func TestShrinkWidths(t *testing.T) {
+	cols := []widthColumn{{"A", 3, nil}, {"B", 20, nil}, {"C", 20, nil}}
+	tests := []struct {
+		name       string
+		candidates []bool
+		opts       []Option
+		want       []int
+	}{
+		// A fits in its fair share so B & C split the rest
+		{"fair share", []bool{true, true, true}, nil, []int{3, 10, 10}},
+		{"shrink floor", []bool{true, true, true}, []Option{WithShrinkFloor(12)}, []int{3, 12, 12}},
+		{"min width", []bool{true, true, true}, []Option{WithMinColumnWidth(11)}, []int{3, 11, 11}},
+		{"candidates only", []bool{false, false, true}, nil, []int{3, 20, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			colWidth := []int{3, 20, 20}
+			shrinkWidths(colWidth, widthTable(false, cols...), tt.candidates, 23, newOptions(tt.opts))
+			if !reflect.DeepEqual(colWidth, tt.want) {
+				t.Errorf("shrinkWidths() = %v, want %v", colWidth, tt.want)
+			}
+		})
+	}
+}
+
+func TestDropColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		index   bool
+		cols    []widthColumn
+		width   int
+		dropped int
+		want    []string
+	}{
+		{
+			name:    "no priority",
+			cols:    []widthColumn{{"AAAAA", 5, nil}, {"BBBBB", 5, nil}},
+			width:   5,
+			dropped: 0,
+			want:    []string{"AAAAA", "BBBBB"},
+		},
+		{
+			name:    "no total width",
+			cols:    []widthColumn{{"AAAAA", 5, tagOptions{"priority": "1"}}, {"BBBBB", 5, nil}},
+			dropped: 0,
+			want:    []string{"AAAAA", "BBBBB"},
+		},
+		{
+			name: "lowest priority rightmost first",
+			cols: []widthColumn{
+				{"AAAAA", 5, tagOptions{"priority": "2"}},
+				{"BBBBB", 5, tagOptions{"priority": "1"}},
+				{"CCCCC", 5, tagOptions{"priority": "1"}},
+			},
+			width:   14,
+			dropped: 1,
+			want:    []string{"AAAAA", "BBBBB"},
+		},
+		{
+			name: "keeps one column",
+			cols: []widthColumn{
+				{"AAAAA", 5, tagOptions{"priority": "1"}},
+				{"BBBBB", 5, tagOptions{"priority": "2"}},
+			},
+			width:   1,
+			dropped: 1,
+			want:    []string{"BBBBB"},
+		},
+		{
+			name:  "keeps the index column",
+			index: true,
+			cols: []widthColumn{
+				{"AAAAA", 5, tagOptions{"priority": "2"}},
+				{"BBBBB", 5, tagOptions{"priority": "1"}},
+			},
+			width:   1,
+			dropped: 1,
+			want:    []string{"#", "AAAAA"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tbl := widthTable(tt.index, tt.cols...)
+			dropped := dropColumns(tbl, newOptions([]Option{WithTotalWidth(tt.width)}))
+			if dropped != tt.dropped {
+				t.Errorf("dropColumns() = %d, want %d", dropped, tt.dropped)
+			}
+			if !reflect.DeepEqual(tbl.fields, tt.want) {
+				t.Errorf("fields = %v, want %v", tbl.fields, tt.want)
+			}
+		})
+	}
+}
+
+func TestHiddenLine(t *testing.T) {
+	tests := []struct {
+		columns int
+		want    string
+	}{
+		{1, "(+1 column hidden)"},
+		{2, "(+2 columns hidden)"},
+		{10, "(+10 columns hidden)"},
+	}
+	for _, tt := range tests {
+		if got := hiddenLine(tt.columns); got != tt.want {
+			t.Errorf("hiddenLine(%d) = %q, want %q", tt.columns, got, tt.want)
+		}
+	}
+}
+
+type priorityRow struct {
+	Name   string `header:"Name,priority=3"`
+	Detail string `header:"Detail,priority=1"`
+	Note   string `header:"Note,priority=2"`
+}
+
+func (r priorityRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestPriorityTable(t *testing.T) {
+	rows := []TableStruct{
+		priorityRow{Name: "alpha", Detail: "some long detail", Note: "a note"},
+	}
+	tests := []struct {
+		name  string
+		width int
+		want  string
+	}{
+		{
+			name:  "natural width",
+			width: 0,
+			want:  "Name  | Detail           | Note  \n=================================\nalpha | some long detail | a note\n",
+		},
+		{
+			name:  "low priority shrunk",
+			width: 24,
+			want:  "Name  | Detail  | Note  \n========================\nalpha | some lo | a note\n",
+		},
+		{
+			name:  "low priority dropped",
+			width: 14,
+			want:  "Name  | Note  \n==============\nalpha | a note\n(+1 column hidden)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateTableString(rows, nil, WithTotalWidth(tt.width))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("GenerateTableString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxColumnWidth(t *testing.T) {
+	long := []TableStruct{colorRow{Name: "alphabetical", Value: "v"}}
+	tests := []struct {
+		name string
+		rows []TableStruct
+		opts []Option
+		want string
+	}{
+		{"unlimited", long, nil, "Name         | Value\n====================\nalphabetical | v    \n"},
+		{"values", long, []Option{WithMaxColumnWidth(6)}, "Name   | Value\n==============\nalpha… | v    \n"},
+		{"ascii ellipsis", long, []Option{WithMaxColumnWidth(6), WithEllipsis("...")}, "Name   | Value\n==============\nalp... | v    \n"},
+		{"headers", long, []Option{WithMaxColumnWidth(3)}, "Na… | Va…\n=========\nal… | v  \n"},
+		{
+			// the maxwidth tag option wins over the default
+			name: "tag",
+			rows: []TableStruct{truncRow{Path: "/src/pkg/gotable.go", ID: "abc123xyz", Note: "abcdefg"}},
+			opts: []Option{WithMaxColumnWidth(3)},
+			want: "Path     | ID      | Note  \n===========================\n…able.go | abc…xyz | abcde…\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateTableString(tt.rows, nil, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("GenerateTableString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFixedWidths(t *testing.T) {
+	long := []TableStruct{colorRow{Name: "alphabetical", Value: "v"}}
+	tests := []struct {
+		name string
+		rows []TableStruct
+		opts []Option
+		want string
+	}{
+		{"pad and truncate", long, []Option{WithFixedWidths(map[string]int{"Name": 6, "Value": 3})}, "Name   | Va…\n============\nalpha… | v  \n"},
+		{"unknown field", long, []Option{WithFixedWidths(map[string]int{"Nope": 6})}, "Name         | Value\n====================\nalphabetical | v    \n"},
+		{
+			// the width tag option wins over WithFixedWidths
+			name: "tag",
+			rows: []TableStruct{fixedRow{Long: "x"}},
+			opts: []Option{WithFixedWidths(map[string]int{"Long": 3})},
+			want: "Long" + strings.Repeat(" ", 47) + "| Empty\n" + strings.Repeat("=", 58) + "\nx" + strings.Repeat(" ", 50) + "|      \n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateTableString(tt.rows, nil, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("GenerateTableString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFullHeaders(t *testing.T) {
+	rows := []TableStruct{colorRow{Name: "abcdefghijkl", Value: "abcdefghijkl"}}
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{"max width", []Option{WithMaxColumnWidth(2)}, "N… | V…\n=======\na… | a…\n"},
+		{"max width full", []Option{WithMaxColumnWidth(2), WithFullHeaders()}, "Name | Value\n============\nabc… | abcd…\n"},
+		{"shrink floor", []Option{WithTotalWidth(12), WithShrinkFloor(2)}, "Name  | Valu\n============\nabcde | abcd\n"},
+		{"shrink floor full", []Option{WithTotalWidth(12), WithShrinkFloor(2), WithFullHeaders()}, "Name | Value\n============\nabcd | abcde\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateTableString(rows, nil, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("GenerateTableString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// wraps returns true if the values of column i are wrapped instead of
+// truncated, either by the wrap tag option or WithWrap
+func wraps(t *tableData, i int, o *options) bool {
+	return o.wrap || (i < len(t.opts) && t.opts[i].has("wrap"))
+}
+
+// wrapWidth returns the width set by the wrap=N tag option of column i or
+// zero if there is none
+func wrapWidth(t *tableData, i int) int {
+	if i >= len(t.opts) {
+		return 0
+	}
+	width, _ := strconv.Atoi(t.opts[i]["wrap"])
+	return width
+}
+
+// multiLine returns true if newlines in the values of column i start a new
+// line, either because it is wrapped or WithMultiLineCells is set
+func multiLine(t *tableData, i int, o *options) bool {
+	return o.multiLine || wraps(t, i, o)
+}
+
+// cellLines returns the lines of a value in column i: each line of the
+// value wrapped to width for wrapped columns, otherwise the value (or each
+// of its lines with WithMultiLineCells) truncated to width unless
+// WithOverflow is set
+func cellLines(t *tableData, i int, value string, width int, o *options) []string {
+	if wraps(t, i, o) {
+		return wrapCell(value, width)
+	}
+	lines := []string{value}
+	if o.multiLine {
+		lines = strings.Split(strings.ReplaceAll(value, "\r\n", "\n"), "\n")
+	}
+	if !o.overflow {
+		for l, line := range lines {
+			lines[l] = truncateCell(t, i, line, width, o)
+		}
+	}
+	return lines
+}
+
+// wrapCell breaks value into lines of at most width cells on word
+// boundaries.  Lines which already fit are kept as is, so are the spaces
+// between the words of a wrapped line.  Words longer than width are split
+// and a newline in the value always starts a new line.
+func wrapCell(value string, width int) []string {
+	lines := []string{}
+	value = strings.ReplaceAll(value, "\r\n", "\n")
+	for _, paragraph := range strings.Split(value, "\n") {
+		if width <= 0 || displayWidth(paragraph) <= width {
+			lines = append(lines, paragraph)
+			continue
+		}
+		lines = append(lines, wrapLine(paragraph, width)...)
+	}
+	return lines
+}
+
+// wrapLine breaks a single line wider than width on its spaces.  The
+// spaces at each break are dropped.
+func wrapLine(value string, width int) []string {
+	lines := []string{}
+	line := ""
+	for value != "" {
+		n := strings.IndexFunc(value, isNotSpace)
+		if n < 0 {
+			break // trailing spaces
+		}
+		space := value[:n]
+		value = value[n:]
+		if n = strings.IndexFunc(value, unicode.IsSpace); n < 0 {
+			n = len(value)
+		}
+		word := value[:n]
+		value = value[n:]
+
+		if line == "" && len(lines) > 0 {
+			space = "" // the line break replaces the spaces
+		}
+		if displayWidth(line+space+word) <= width {
+			line += space + word
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		for displayWidth(word) > width {
+			cut := cutWidth(word, width)
+			if cut == 0 {
+				cut = cutWidth(word, 2) // too wide for the column
+			}
+			lines = append(lines, word[:cut])
+			word = word[cut:]
+		}
+		line = word
+	}
+	return append(lines, line)
+}
+
+// isNotSpace returns true if r is not white space
+func isNotSpace(r rune) bool {
+	return !unicode.IsSpace(r)
+}
+
+// lineWidth returns the width of the longest line of value
+func lineWidth(value string) int {
+	width := 0
+	for _, line := range strings.Split(value, "\n") {
+		if n := displayWidth(strings.TrimSuffix(line, "\r")); n > width {
+			width = n
+		}
+	}
+	return width
+}
@@ -0,0 +1,117 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWrapCell(t *testing.T) {
+	tests := []struct {
+		value string
+		width int
+		want  []string
+	}{
+		{"the quick brown fox", 10, []string{"the quick", "brown fox"}},
+		{"supercalifragilistic", 8, []string{"supercal", "ifragili", "stic"}},
+		{"a\nb c", 10, []string{"a", "b c"}},
+		{"a\r\nb", 10, []string{"a", "b"}},
+		{"名前名前", 5, []string{"名前", "名前"}},
+		{"", 5, []string{""}},
+		{"a  b   c", 10, []string{"a  b   c"}},
+		{"  1.5 ", 10, []string{"  1.5 "}},
+		{"the  quick  brown fox", 12, []string{"the  quick", "brown fox"}},
+		{"  lead  two  spaces here", 12, []string{"  lead  two", "spaces here"}},
+	}
+	for _, tt := range tests {
+		if got := wrapCell(tt.value, tt.width); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("wrapCell(%q, %d) = %q, want %q", tt.value, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestMultiLineCellsCRLF(t *testing.T) {
+	rows := []TableStruct{colorRow{Name: "line1\r\nline2", Value: "x"}}
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{
+			name: "multi-line",
+			opts: []Option{WithMultiLineCells()},
+			want: "Name  | Value\n=============\nline1 | x    \nline2 |      \n",
+		},
+		{
+			name: "wrap",
+			opts: []Option{WithWrap()},
+			want: "Name  | Value\n=============\nline1 | x    \nline2 |      \n",
+		},
+		{
+			name: "marker",
+			opts: []Option{WithNewlineMarker("␤")},
+			want: "Name        | Value\n===================\nline1␤line2 | x    \n",
+		},
+		{
+			name: "escaped",
+			want: "Name           | Value\n======================\nline1\\r\\nline2 | x    \n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateTableString(rows, nil, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("GenerateTableString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type wrapDecimalRow struct {
+	Note  string  `header:"Note,maxwidth=12"`
+	Price float64 `header:"Price,align=decimal"`
+}
+
+func (r wrapDecimalRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestWrapDecimal(t *testing.T) {
+	rows := []TableStruct{
+		wrapDecimalRow{Note: "a  b   c", Price: 1.5},
+		wrapDecimalRow{Note: "the  quick  brown fox", Price: 10},
+		wrapDecimalRow{Note: "x", Price: 100.25},
+	}
+	got, err := GenerateTableString(rows, nil, WithWrap())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Note         |  Price\n" +
+		"=====================\n" +
+		"a  b   c     |   1.5 \n" +
+		"the  quick   |  10   \n" +
+		"brown fox    |       \n" +
+		"x            | 100.25\n"
+	if got != want {
+		t.Errorf("GenerateTableString() =\n%q\nwant\n%q", got, want)
+	}
+}
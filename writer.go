@@ -0,0 +1,167 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// TableWriter generates a table from rows written one at a time.  It is
+// also an io.Writer so text such as log messages can be written between
+// the rows.
+type TableWriter struct {
+	w      io.Writer
+	fields []string
+	o      *options
+
+	// rows written by WriteRow, or nil rows written by WriteRowImmediate
+	// before the header, which are not printed yet
+	rows []TableStruct
+
+	// set once WriteRowImmediate has printed the header
+	colWidth  []int
+	intWidth  []int
+	fracWidth []int
+	written   int
+}
+
+// NewTableWriter returns a TableWriter which prints the given fields to w
+func NewTableWriter(w io.Writer, fields []string, opts ...Option) *TableWriter {
+	o := newOptions(opts)
+	o.table = true
+	return &TableWriter{
+		w:      w,
+		fields: fields,
+		o:      o,
+		rows:   []TableStruct{},
+	}
+}
+
+// WriteRow buffers row until Flush so the column widths can fit every row.
+// Once WriteRowImmediate has printed the header, buffered rows are printed
+// before the next immediate row or text so they keep their order.
+func (tw *TableWriter) WriteRow(row TableStruct) error {
+	tw.rows = append(tw.rows, row)
+	return nil
+}
+
+// WriteRowImmediate prints row, after any rows buffered by WriteRow, right
+// away.  The column widths are fixed by the first rows: the
+// WithColumnWidths widths if set, otherwise the width of the header & the
+// rows up to the first non-nil one.  Decimal points stay aligned as long as
+// later values fit the same widths.  Longer values are truncated unless
+// WithOverflow is set and rows with a different number of columns are an
+// error.  Aggregate footers are not supported.
+func (tw *TableWriter) WriteRowImmediate(row TableStruct) error {
+	rows := append(tw.rows, row)
+	tw.rows = []TableStruct{}
+	if tw.colWidth == nil && allNilRows(rows) {
+		// nil rows can't define the layout so wait for the first real one
+		tw.rows = rows
+		return nil
+	}
+	return tw.writeRows(rows)
+}
+
+// Write prints p as is, after any rows buffered by WriteRow once
+// WriteRowImmediate has printed the header.  Before that, the text is
+// printed ahead of the table.
+func (tw *TableWriter) Write(p []byte) (int, error) {
+	if tw.colWidth != nil && len(tw.rows) > 0 {
+		rows := tw.rows
+		tw.rows = []TableStruct{}
+		if err := tw.writeRows(rows); err != nil {
+			return 0, err
+		}
+	}
+	return tw.w.Write(p)
+}
+
+// writeRows prints rows with the column widths of the first rows printed,
+// printing the header first if needed
+func (tw *TableWriter) writeRows(rows []TableStruct) error {
+	t, err := buildTable(rows, tw.fields, tw.o)
+	if err != nil {
+		return err
+	}
+	t = escapeTable(t, tw.o)
+	if tw.colWidth == nil {
+		tw.intWidth, tw.fracWidth = decimalWidths(t)
+		t = padDecimals(t, tw.intWidth, tw.fracWidth)
+		fitTerminal(tw.w, tw.o)
+		tw.colWidth = columnWidths(t, tw.o)
+		if err := printHeader(tw.w, t, tw.colWidth, tw.o); err != nil {
+			return err
+		}
+	}
+	for _, values := range t.rows {
+		if len(values) != len(tw.colWidth) {
+			return fmt.Errorf("Row has %d columns, expected %d", len(values), len(tw.colWidth))
+		}
+	}
+	t = padDecimals(t, tw.intWidth, tw.fracWidth)
+	for _, values := range t.rows {
+		tw.written++
+		if t.hasIndex {
+			values[0] = strconv.Itoa(tw.written)
+		}
+		if _, err := fmt.Fprintln(tw.w, formatRow(t, values, tw.colWidth, false, tw.o)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// allNilRows returns true if every row is nil
+func allNilRows(rows []TableStruct) bool {
+	for _, row := range rows {
+		if !isNilRow(row) {
+			return false
+		}
+	}
+	return true
+}
+
+// Flush prints the rows buffered by WriteRow.  If WriteRowImmediate was
+// used, the buffered rows are printed with its column widths followed by
+// the WithRowCount line.
+func (tw *TableWriter) Flush() error {
+	rows := tw.rows
+	tw.rows = []TableStruct{}
+
+	if tw.colWidth == nil {
+		t, err := buildTable(rows, tw.fields, tw.o)
+		if err != nil {
+			return err
+		}
+		return generateTable(tw.w, t, tw.o)
+	}
+
+	if len(rows) > 0 {
+		if err := tw.writeRows(rows); err != nil {
+			return err
+		}
+	}
+	if tw.o.rowCount {
+		_, err := fmt.Fprintln(tw.w, rowCountLine(tw.written))
+		return err
+	}
+	return nil
+}
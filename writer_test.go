@@ -0,0 +1,160 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+)
+
+type wideRow struct {
+	Name  string `header:"Name"`
+	Value string `header:"Value"`
+	Extra string `header:"Extra"`
+}
+
+func (r wideRow) GetHeader(fieldName string) (string, error) {
+	return GetHeaderTag(reflect.ValueOf(r), fieldName)
+}
+
+func TestWriteRowImmediateNilFirst(t *testing.T) {
+	var buf bytes.Buffer
+	tw := NewTableWriter(&buf, nil)
+	var nilRow *colorRow
+	if err := tw.WriteRowImmediate(nilRow); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.WriteRowImmediate(colorRow{Name: "a", Value: "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := "Name | Value\n============\na    | b    \n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteRowImmediateNilRows(t *testing.T) {
+	var buf bytes.Buffer
+	tw := NewTableWriter(&buf, nil, WithNilRows(), WithNullPlaceholder("-"))
+	var nilRow *colorRow
+	for _, row := range []TableStruct{nilRow, colorRow{Name: "a", Value: "b"}} {
+		if err := tw.WriteRowImmediate(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "Name | Value\n============\n-    | -    \na    | b    \n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteRowImmediateColumnMismatch(t *testing.T) {
+	var buf bytes.Buffer
+	tw := NewTableWriter(&buf, nil)
+	if err := tw.WriteRowImmediate(colorRow{Name: "a", Value: "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.WriteRowImmediate(wideRow{Name: "c", Value: "d", Extra: "e"}); err == nil {
+		t.Error("expected an error for a row with more columns")
+	}
+}
+
+func TestTableWriterOrder(t *testing.T) {
+	var buf bytes.Buffer
+	tw := NewTableWriter(&buf, nil)
+	steps := []func() error{
+		func() error { return tw.WriteRowImmediate(colorRow{Name: "a", Value: "1"}) },
+		func() error { return tw.WriteRow(colorRow{Name: "b", Value: "2"}) },
+		func() error { return tw.WriteRowImmediate(colorRow{Name: "c", Value: "3"}) },
+		func() error { return tw.WriteRow(colorRow{Name: "d", Value: "4"}) },
+		func() error { _, err := tw.Write([]byte("-- log --\n")); return err },
+		func() error { return tw.WriteRow(colorRow{Name: "e", Value: "5"}) },
+		tw.Flush,
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "Name | Value\n============\n" +
+		"a    | 1    \nb    | 2    \nc    | 3    \nd    | 4    \n-- log --\ne    | 5    \n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestTableWriterIsWriter(t *testing.T) {
+	var buf bytes.Buffer
+	var w io.Writer = NewTableWriter(&buf, nil)
+	if _, err := fmt.Fprintf(w, "%d rows\n", 0); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "0 rows\n" {
+		t.Errorf("got %q", buf.String())
+	}
+}
+
+func TestWriteRowImmediateDecimal(t *testing.T) {
+	tests := []struct {
+		name     string
+		prices   []float64
+		buffered int // rows written by WriteRow first
+		want     string
+	}{
+		{
+			name:   "first row sets widths",
+			prices: []float64{10.25, 1.5, 100},
+			want: "Name | Price\n============\n" +
+				"a    | 10.25\nb    |  1.5 \nc    | 100  \n",
+		},
+		{
+			name:     "buffered rows align together",
+			prices:   []float64{1.5, 10.25},
+			buffered: 1,
+			want: "Name | Price\n============\n" +
+				"a    |  1.5 \nb    | 10.25\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tw := NewTableWriter(&buf, nil)
+			for i, price := range tt.prices {
+				row := priceRow{Name: string(rune('a' + i)), Price: price}
+				var err error
+				if i < tt.buffered {
+					err = tw.WriteRow(row)
+				} else {
+					err = tw.WriteRowImmediate(row)
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			if buf.String() != tt.want {
+				t.Errorf("got %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	yamlInt   = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	yamlFloat = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+
+	// leading zeros are dropped or read as octal by YAML 1.1 parsers
+	yamlLeadingZero = regexp.MustCompile(`^[-+]?0[0-9]`)
+
+	// hex, octal & binary ints such as the base & prefix tag options render
+	yamlPrefixed = regexp.MustCompile(`^[-+]?0([xX][0-9a-fA-F_]+|[oO][0-7_]+|[bB][01_]+)$`)
+
+	// plain scalars which a YAML parser would not read back as a string
+	yamlReserved = map[string]bool{
+		"":      true,
+		"~":     true,
+		"null":  true,
+		"true":  true,
+		"false": true,
+		"yes":   true,
+		"no":    true,
+		"on":    true,
+		"off":   true,
+		"y":     true,
+		"n":     true,
+	}
+)
+
+// Generates YAML: a sequence of mappings of header name to value
+func GenerateYAML(w io.Writer, tables []TableStruct, fields []string, opts ...Option) error {
+	t, err := buildTable(tables, fields, newOptions(opts))
+	if err != nil {
+		return err
+	}
+	return generateYAML(w, t)
+}
+
+func generateYAML(w io.Writer, t *tableData) error {
+	if len(t.rows) == 0 {
+		_, err := fmt.Fprintln(w, "[]")
+		return err
+	}
+
+	for _, row := range t.rows {
+		if len(row) == 0 {
+			if _, err := fmt.Fprintln(w, "- {}"); err != nil {
+				return err
+			}
+			continue
+		}
+		for i, value := range row {
+			prefix := "  "
+			if i == 0 {
+				prefix = "- "
+			}
+			_, err := fmt.Fprintf(w, "%s%s: %s\n", prefix, yamlString(t.headers[i]), yamlValue(value))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// yamlValue emits numbers and booleans unquoted and everything else as a
+// string.  Numbers with leading zeros are strings such as zip codes.
+func yamlValue(value string) string {
+	if yamlLeadingZero.MatchString(value) {
+		return yamlString(value)
+	}
+	if value == "true" || value == "false" || yamlInt.MatchString(value) || yamlFloat.MatchString(value) {
+		return value
+	}
+	return yamlString(value)
+}
+
+// yamlString returns value as a plain scalar when that is unambiguous,
+// otherwise as a double quoted string
+func yamlString(value string) string {
+	if yamlNeedsQuotes(value) {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+func yamlNeedsQuotes(value string) bool {
+	if yamlReserved[strings.ToLower(value)] {
+		return true
+	}
+	if yamlInt.MatchString(value) || yamlFloat.MatchString(value) || yamlPrefixed.MatchString(value) {
+		return true
+	}
+	if strings.TrimSpace(value) != value {
+		return true
+	}
+	if strings.ContainsAny(value[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return true
+	}
+	if strings.Contains(value, ": ") || strings.Contains(value, " #") || strings.HasSuffix(value, ":") {
+		return true
+	}
+	for _, r := range value {
+		if r < ' ' || r == 0x7f {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package gotable
+
+/*
+ * GoTable
+ * Copyright (c) 2020-2021 Aaron Turner  <synfinatic at gmail dot com>
+ *
+ * This program is free software: you can redistribute it
+ * and/or modify it under the terms of the GNU General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or with the authors permission any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+import (
+	"testing"
+)
+
+func TestYAMLValue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"123", "123"},
+		{"-1.5", "-1.5"},
+		{"0", "0"},
+		{"0.5", "0.5"},
+		{"true", "true"},
+		{"01234", `"01234"`},
+		{"007", `"007"`},
+		{"-012", `"-012"`},
+		{"00.5", `"00.5"`},
+		{"0x1f", `"0x1f"`},
+		{"0o17", `"0o17"`},
+		{"0b101", `"0b101"`},
+		{"0xzz", "0xzz"},
+		{"null", `"null"`},
+		{"plain", "plain"},
+	}
+	for _, tt := range tests {
+		if got := yamlValue(tt.value); got != tt.want {
+			t.Errorf("yamlValue(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}